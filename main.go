@@ -6,6 +6,10 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/drone/drone-artifactory/plugin"
 
 	"github.com/kelseyhightower/envconfig"
@@ -15,6 +19,13 @@ import (
 func main() {
 	logrus.SetFormatter(new(formatter))
 
+	// settings from the config file apply unless set in the environment
+	if path := os.Getenv(plugin.ConfigFileEnv); path != "" {
+		if err := plugin.LoadConfigFile(path); err != nil {
+			logrus.Fatalln(err)
+		}
+	}
+
 	var args plugin.Args
 	if err := envconfig.Process("", &args); err != nil {
 		logrus.Fatalln(err)
@@ -29,7 +40,12 @@ func main() {
 		logrus.SetLevel(logrus.TraceLevel)
 	}
 
-	if err := plugin.Exec(context.Background(), args); err != nil {
+	// cancel the running commands when the pipeline is cancelled
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := plugin.Exec(ctx, args); err != nil {
+		stop()
 		logrus.Fatalln(err)
 	}
 }
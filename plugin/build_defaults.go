@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Templates used for the build name and number when neither they nor a
+// custom template are set, rendered against the Pipeline.
+const (
+	defaultBuildNameTemplate   = "{{.Repo.Slug}}{{with .Stage.Name}}-{{.}}{{end}}"
+	defaultBuildNumberTemplate = "{{with .Build.Number}}{{.}}{{end}}"
+)
+
+// applyBuildDefaults sets the build name and number of args, when unset,
+// from their templates rendered against the pipeline metadata. Fields whose
+// template renders to an empty string are left unset, as are those of steps
+// run without pipeline metadata.
+func applyBuildDefaults(args *Args) error {
+	if !hasPipelineMetadata(args.Pipeline) {
+		return nil
+	}
+	if args.BuildName == "" {
+		name, err := renderBuildTemplate("build_name_template", args.BuildNameTemplate, defaultBuildNameTemplate, args.Pipeline)
+		if err != nil {
+			return err
+		}
+		if args.BuildNameTemplate == "" {
+			// the repository slug holds a "/", which build names should not
+			name = strings.ReplaceAll(name, "/", "-")
+		}
+		if name != "" {
+			logrus.Printf("Using build name %q from the pipeline metadata\n", name)
+			args.BuildName = name
+		}
+	}
+	if args.BuildNumber == "" {
+		number, err := renderBuildTemplate("build_number_template", args.BuildNumberTemplate, defaultBuildNumberTemplate, args.Pipeline)
+		if err != nil {
+			return err
+		}
+		if number != "" {
+			logrus.Printf("Using build number %q from the pipeline metadata\n", number)
+			args.BuildNumber = number
+		}
+	}
+	return nil
+}
+
+// hasPipelineMetadata reports whether the step runs in a pipeline setting
+// the repository or the build number.
+func hasPipelineMetadata(pipeline Pipeline) bool {
+	return pipeline.Repo.Slug != "" || pipeline.Build.Number != 0
+}
+
+func renderBuildTemplate(name, text, defaultText string, pipeline Pipeline) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %s", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, pipeline); err != nil {
+		return "", fmt.Errorf("error rendering %s: %s", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
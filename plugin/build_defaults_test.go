@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func testPipeline() Pipeline {
+	var pipeline Pipeline
+	pipeline.Repo.Slug = "octocat/hello-world"
+	pipeline.Stage.Name = "build"
+	pipeline.Build.Number = 42
+	pipeline.Commit.Branch = "main"
+	return pipeline
+}
+
+func TestApplyBuildDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       Args
+		wantName   string
+		wantNumber string
+	}{
+		{
+			name:       "pipeline defaults",
+			args:       Args{Pipeline: testPipeline(), PublishBuildInfo: true},
+			wantName:   "octocat-hello-world-build",
+			wantNumber: "42",
+		},
+		{
+			name:       "publishing command",
+			args:       Args{Pipeline: testPipeline(), BuildTool: MvnCmd, Command: Publish},
+			wantName:   "octocat-hello-world-build",
+			wantNumber: "42",
+		},
+		{
+			name:       "publishing command in a command list",
+			args:       Args{Pipeline: testPipeline(), BuildTool: NpmCmd, Commands: []string{"build", Publish}},
+			wantName:   "octocat-hello-world-build",
+			wantNumber: "42",
+		},
+		{
+			name:       "build command",
+			args:       Args{Pipeline: testPipeline(), BuildTool: MvnCmd, Command: "build"},
+			wantName:   "octocat-hello-world-build",
+			wantNumber: "42",
+		},
+		{
+			name:       "scan",
+			args:       Args{Pipeline: testPipeline(), Command: "scan"},
+			wantName:   "octocat-hello-world-build",
+			wantNumber: "42",
+		},
+		{
+			name:       "explicit values win",
+			args:       Args{Pipeline: testPipeline(), PublishBuildInfo: true, BuildName: RtBuildName, BuildNumber: RtBuildNumber},
+			wantName:   RtBuildName,
+			wantNumber: RtBuildNumber,
+		},
+		{
+			name: "templates",
+			args: Args{
+				Pipeline:            testPipeline(),
+				PublishBuildInfo:    true,
+				BuildNameTemplate:   "{{.Repo.Slug}}/{{.Commit.Branch}}",
+				BuildNumberTemplate: "{{.Build.Number}}-{{.Stage.Name}}",
+			},
+			wantName:   "octocat/hello-world/main",
+			wantNumber: "42-build",
+		},
+		{
+			name: "no pipeline metadata",
+			args: Args{PublishBuildInfo: true},
+		},
+		{
+			name: "stage without repository or build number",
+			args: func() Args {
+				var args Args
+				args.Command = "scan"
+				args.Pipeline.Stage.Name = "build"
+				return args
+			}(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := tc.args
+			if err := applyBuildDefaults(&args); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if args.BuildName != tc.wantName {
+				t.Errorf("Expected build name %q, got %q", tc.wantName, args.BuildName)
+			}
+			if args.BuildNumber != tc.wantNumber {
+				t.Errorf("Expected build number %q, got %q", tc.wantNumber, args.BuildNumber)
+			}
+		})
+	}
+}
+
+func TestApplyBuildDefaultsInvalidTemplate(t *testing.T) {
+	for _, tmpl := range []string{"{{.Repo.Slug", "{{.Repo.Unknown}}"} {
+		args := Args{Pipeline: testPipeline(), PublishBuildInfo: true, BuildNameTemplate: tmpl}
+		err := applyBuildDefaults(&args)
+		if err == nil || !strings.Contains(err.Error(), "build_name_template") {
+			t.Errorf("Expected template error for %q, got %v", tmpl, err)
+		}
+	}
+}
+
+func TestHandleRtCommandsUsesPipelineBuildDefaults(t *testing.T) {
+	args := Args{
+		Pipeline:    testPipeline(),
+		Command:     "publish-build-info",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "jf rt build-publish octocat-hello-world-build 42"
+	if len(executor.commands) != 2 || !strings.HasPrefix(executor.commands[1], want) {
+		t.Errorf("Expected %q, got %v", want, executor.commands)
+	}
+}
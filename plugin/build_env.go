@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"reflect"
+	"strings"
+)
+
+const buildCollectEnv = "build-collect-env"
+
+// defaultEnvExclude are the patterns jf excludes from the collected
+// environment by default, and the ones excluded on top of them.
+var defaultEnvExclude = []string{
+	"*password*", "*psw*", "*secret*", "*key*", "*token*", "*auth*",
+	"*TOKEN*", "*SECRET*",
+}
+
+// GetBuildCollectEnvCommandArgs returns the jf command recording the
+// environment variables in the build info.
+func GetBuildCollectEnvCommandArgs(args Args) []string {
+	return []string{"rt", buildCollectEnv, args.BuildName, args.BuildNumber}
+}
+
+// withBuildInfoSteps adds the optional build info collection steps to
+// cmdList, before every build-publish.
+func withBuildInfoSteps(args Args, cmdList []Command) []Command {
+	return withCollectEnv(args, withGitInfo(args, cmdList))
+}
+
+// withCollectEnv inserts the build-collect-env command before every
+// build-publish of cmdList and sets the env filters of the build-publish
+// when PLUGIN_COLLECT_ENV is set.
+func withCollectEnv(args Args, cmdList []Command) []Command {
+	if !args.CollectEnv {
+		return cmdList
+	}
+	var result []Command
+	for _, cmd := range cmdList {
+		if cmd.isJfCommand(isBuildPublish) {
+			if !precededBy(result, isBuildCollectEnv) {
+				result = append(result, Command{Args: GetBuildCollectEnvCommandArgs(args)})
+			}
+			cmd.Args = withEnvFilters(args, cmd.Args)
+		}
+		result = append(result, cmd)
+	}
+	return result
+}
+
+// withEnvFilters returns a copy of the build-publish command filtering the
+// collected environment.
+func withEnvFilters(args Args, cmdArgs []string) []string {
+	for _, arg := range cmdArgs {
+		if strings.HasPrefix(arg, "--env-exclude=") {
+			return cmdArgs
+		}
+	}
+	filtered := append([]string{}, cmdArgs...)
+	if args.EnvInclude != "" {
+		filtered = append(filtered, "--env-include="+args.EnvInclude)
+	}
+	return append(filtered, "--env-exclude="+envExcludePatterns(args))
+}
+
+// envExcludePatterns returns the patterns of the variables left out of the
+// build info. The variables holding the plugin secrets are always excluded,
+// since they are set in the environment of jf.
+func envExcludePatterns(args Args) string {
+	var patterns []string
+	if args.EnvExclude != "" {
+		patterns = strings.Split(args.EnvExclude, ";")
+	} else {
+		patterns = append(patterns, defaultEnvExclude...)
+	}
+	for _, name := range secretEnvNames() {
+		if !containsArg(patterns, name) {
+			patterns = append(patterns, name)
+		}
+	}
+	return strings.Join(patterns, ";")
+}
+
+// secretEnvNames returns the environment variables of the secret fields of
+// Args and of the credentials passed to jf.
+func secretEnvNames() []string {
+	names := append([]string{}, secretEnvVars...)
+	t := reflect.TypeOf(Args{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("envconfig")
+		if field.Tag.Get("secret") == "true" && name != "" && !containsArg(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// precededBy reports whether one of the build info steps at the end of
+// cmdList matches step.
+func precededBy(cmdList []Command, step func([]string) bool) bool {
+	for i := len(cmdList) - 1; i >= 0; i-- {
+		if cmdList[i].isJfCommand(step) {
+			return true
+		}
+		if !cmdList[i].isJfCommand(isBuildAddGit) && !cmdList[i].isJfCommand(isBuildCollectEnv) {
+			return false
+		}
+	}
+	return false
+}
+
+func isBuildCollectEnv(cmdArgs []string) bool {
+	subCmd := jfSubcommand(cmdArgs)
+	return subCmd == "rt "+buildCollectEnv || subCmd == "rt bce"
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithCollectEnv(t *testing.T) {
+	args := Args{BuildName: RtBuildName, BuildNumber: RtBuildNumber, CollectEnv: true, AddGitInfo: true}
+	publish := []string{"rt", "build-publish", RtBuildName, RtBuildNumber}
+	cmdList := jfCommands([][]string{{"mvn", "deploy"}, publish})
+
+	got := withBuildInfoSteps(args, cmdList)
+	want := []string{
+		"mvn deploy",
+		"rt build-add-git t2 v1.0",
+		"rt build-collect-env t2 v1.0",
+		"rt build-publish t2 v1.0 --env-exclude=" + envExcludePatterns(args),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d commands, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if commandLine(got[i]) != want[i] {
+			t.Errorf("Command mismatch at index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+	if len(publish) != 4 {
+		t.Errorf("Expected the original command to be left unchanged, got %q", publish)
+	}
+
+	if again := withBuildInfoSteps(args, got); len(again) != len(got) || len(again[3].Args) != len(got[3].Args) {
+		t.Errorf("Expected the build info steps to be added once, got %q", again)
+	}
+}
+
+func TestEnvExcludePatterns(t *testing.T) {
+	patterns := strings.Split(envExcludePatterns(Args{}), ";")
+	for _, want := range []string{"*TOKEN*", "*SECRET*", "PLUGIN_PASSWORD", "PLUGIN_ACCESS_TOKEN",
+		"PLUGIN_API_KEY", "PLUGIN_PEM_FILE_CONTENTS"} {
+		if !containsArg(patterns, want) {
+			t.Errorf("Expected default exclusions to contain %q, got %q", want, patterns)
+		}
+	}
+
+	patterns = strings.Split(envExcludePatterns(Args{EnvExclude: "*INTERNAL*"}), ";")
+	if patterns[0] != "*INTERNAL*" || containsArg(patterns, "*TOKEN*") {
+		t.Errorf("Expected custom exclusions to replace the defaults, got %q", patterns)
+	}
+	if !containsArg(patterns, "PLUGIN_PASSWORD") {
+		t.Errorf("Expected the plugin secrets to always be excluded, got %q", patterns)
+	}
+}
+
+func TestExecUploadCollectsEnv(t *testing.T) {
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/app.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		CollectEnv:       true,
+		EnvInclude:       "CI_*",
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(executor.commands) != 5 {
+		t.Fatalf("Expected 5 commands, got %d: %v", len(executor.commands), executor.commands)
+	}
+	if executor.commands[3] != "jf rt build-collect-env t2 v1.0" {
+		t.Errorf("Expected build-collect-env before publishing, got %q", executor.commands[3])
+	}
+	if !strings.Contains(executor.commands[4], "--env-include=CI_* --env-exclude=") {
+		t.Errorf("Expected env filters on build-publish, got %q", executor.commands[4])
+	}
+}
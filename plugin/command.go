@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"strings"
+)
+
+// Command is one of the programs run by a step: jf, or a tool jf does not
+// run itself, such as helm package.
+type Command struct {
+	// Program is the program run, jf when empty.
+	Program string
+	// Args are the arguments passed to the program.
+	Args []string
+	// Dir is the directory the command runs in, the working directory of
+	// the step when empty.
+	Dir string
+}
+
+// jfCommands returns the commands running jf with every entry of cmdList.
+func jfCommands(cmdList [][]string) []Command {
+	commands := make([]Command, 0, len(cmdList))
+	for _, cmdArgs := range cmdList {
+		commands = append(commands, Command{Args: cmdArgs})
+	}
+	return commands
+}
+
+// jfBuilder returns the RtCommandBuilder of a builder rendering jf commands
+// only.
+func jfBuilder(build func(args Args) ([][]string, error)) RtCommandBuilder {
+	return func(args Args) ([]Command, error) {
+		cmdList, err := build(args)
+		return jfCommands(cmdList), err
+	}
+}
+
+// IsJf reports whether the command runs jf.
+func (c Command) IsJf() bool {
+	return c.Program == ""
+}
+
+// isJfCommand reports whether the command runs jf with arguments matching
+// match.
+func (c Command) isJfCommand(match func(cmdArgs []string) bool) bool {
+	return c.IsJf() && match(c.Args)
+}
+
+// Argv returns the program and the arguments of the command.
+func (c Command) Argv() []string {
+	program := c.Program
+	if program == "" {
+		program = getJfrogBin()
+	}
+	return append([]string{program}, c.Args...)
+}
+
+// String returns the command line of the command, changing to its
+// directory first when it has one.
+func (c Command) String() string {
+	line := strings.Join(c.Argv(), " ")
+	if c.Dir != "" {
+		line = "cd " + c.Dir + " && " + line
+	}
+	return line
+}
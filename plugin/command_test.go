@@ -0,0 +1,44 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+// commandLine returns the command line of cmd as the tests expect it: the
+// arguments of jf commands, or the program and arguments of other commands.
+func commandLine(cmd Command) string {
+	if cmd.IsJf() {
+		return strings.Join(cmd.Args, " ")
+	}
+	return strings.Join(append([]string{cmd.Program}, cmd.Args...), " ")
+}
+
+func TestCommandString(t *testing.T) {
+	tests := []struct {
+		cmd  Command
+		want string
+	}{
+		{cmd: Command{Args: []string{"rt", "u", "a.jar"}}, want: getJfrogBin() + " rt u a.jar"},
+		{cmd: Command{Program: HelmCmd, Args: []string{"package", "."}}, want: "helm package ."},
+		{cmd: Command{Args: []string{TfCmd, Publish}, Dir: "infra"}, want: "cd infra && " + getJfrogBin() + " terraform publish"},
+	}
+	for _, tc := range tests {
+		if got := tc.cmd.String(); got != tc.want {
+			t.Errorf("Expected %q, got %q", tc.want, got)
+		}
+	}
+}
+
+func TestJfBuilder(t *testing.T) {
+	build := jfBuilder(func(args Args) ([][]string, error) {
+		return [][]string{{"config", "add", tmpServerId}, {"rt", "ping"}}, nil
+	})
+	cmdList, err := build(Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cmdList) != 2 || !cmdList[0].IsJf() || commandLine(cmdList[1]) != "rt ping" {
+		t.Errorf("Expected two jf commands, got %v", cmdList)
+	}
+}
@@ -0,0 +1,241 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the settings file read by LoadConfigFile.
+const ConfigFileEnv = "PLUGIN_CONFIG_FILE"
+
+// defaultConfigSection is the section applied when the step runs no command,
+// which uploads files.
+const defaultConfigSection = "upload"
+
+// LoadConfigFile reads the YAML or JSON settings file at path and sets the
+// PLUGIN_* environment variable of every setting it contains, unless the
+// variable is already set to a non-empty value, so that envconfig.Process
+// reads the file as a base layer under the environment.
+//
+// Top-level keys apply to every command. A key holding a map is a section
+// named after a build tool or a command, such as maven or promote, applied
+// only when the step runs that build tool or command, and taking precedence
+// over the top-level keys.
+func LoadConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %s", err)
+	}
+	settings, err := parseConfigFile(content)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for name, value := range settings {
+		if _, ok := lookupEnvSetting(name); ok {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("error applying config file setting %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// lookupEnvSetting returns the value of the environment variable name,
+// treating a variable set to an empty string as unset, as the runner sets
+// one for every setting of the step.
+func lookupEnvSetting(name string) (string, bool) {
+	value, ok := os.LookupEnv(name)
+	return value, ok && value != ""
+}
+
+// parseConfigFile returns the environment variables of the settings in
+// content that apply to the selected commands, keyed by name. Values are
+// taken verbatim from the file, so that 1.10 or 010 are not read as numbers.
+func parseConfigFile(content []byte) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{}
+	if len(doc.Content) == 0 {
+		return settings, nil
+	}
+	root := resolveConfigNode(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the settings must be a map")
+	}
+
+	sections := map[string]*yaml.Node{}
+	var problems []string
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, resolveConfigNode(root.Content[i+1])
+		if value.Kind == yaml.MappingNode {
+			name, err := configSectionName(key)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			sections[name] = value
+			continue
+		}
+		if err := addConfigSetting(settings, key, value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	selected := selectedConfigSections(settings)
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	setBy := map[string]string{}
+	for _, name := range names {
+		sectionSettings := map[string]string{}
+		section := sections[name]
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			key, value := section.Content[i].Value, resolveConfigNode(section.Content[i+1])
+			if err := addConfigSetting(sectionSettings, key, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			}
+		}
+		if !selected[name] {
+			continue
+		}
+		for key, value := range sectionSettings {
+			// settings are shared by all the commands of the step
+			if other, ok := setBy[key]; ok && settings[key] != value {
+				problems = append(problems, fmt.Sprintf("%s is set differently in sections %s and %s", key, other, name))
+			}
+			setBy[key] = name
+			settings[key] = value
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return settings, nil
+}
+
+// addConfigSetting validates a setting against the fields of Args and
+// records its environment variable.
+func addConfigSetting(settings map[string]string, key string, value *yaml.Node) error {
+	name := configEnvName(key)
+	field, ok := configFields()[name]
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	switch value.Kind {
+	case yaml.MappingNode:
+		return fmt.Errorf("setting %q must not be a map", key)
+	case yaml.SequenceNode:
+		if field.Type.Kind() != reflect.Slice {
+			return fmt.Errorf("setting %q must not be a list", key)
+		}
+		items := make([]string, 0, len(value.Content))
+		for _, item := range value.Content {
+			item = resolveConfigNode(item)
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("setting %q must be a list of values", key)
+			}
+			items = append(items, item.Value)
+		}
+		settings[name] = strings.Join(items, ",")
+	case yaml.ScalarNode:
+		if value.Tag == "!!null" {
+			return nil
+		}
+		settings[name] = value.Value
+	default:
+		return fmt.Errorf("setting %q has an unsupported value", key)
+	}
+	return nil
+}
+
+// resolveConfigNode returns the node an alias refers to, or node itself.
+func resolveConfigNode(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// selectedConfigSections returns the sections applying to the build tool
+// and commands selected in the environment or in the top-level settings.
+func selectedConfigSections(settings map[string]string) map[string]bool {
+	setting := func(name string) string {
+		if value, ok := lookupEnvSetting(name); ok {
+			return value
+		}
+		return settings[name]
+	}
+
+	selected := map[string]bool{}
+	buildTool := normalizeRtName(setting("PLUGIN_BUILD_TOOL"))
+	if alias, ok := rtBuildToolAliases[buildTool]; ok {
+		buildTool = alias
+	}
+	if buildTool != "" {
+		selected[buildTool] = true
+	}
+
+	var commands []string
+	if value := setting("PLUGIN_COMMANDS"); value != "" {
+		commands = strings.Split(value, ",")
+	} else if value := setting("PLUGIN_COMMAND"); value != "" || buildTool != "" {
+		commands = []string{value}
+	}
+	for _, command := range commands {
+		if rtCmd, err := LookupRtCommand(buildTool, command); err == nil {
+			selected[rtCmd.Name] = true
+		}
+	}
+	if len(commands) == 0 {
+		selected[defaultConfigSection] = true
+	}
+	return selected
+}
+
+// configSectionName returns the build tool or command named by a section.
+func configSectionName(key string) (string, error) {
+	name := normalizeRtName(key)
+	if alias, ok := rtBuildToolAliases[name]; ok {
+		name = alias
+	}
+	if isRtBuildTool(name) {
+		return name, nil
+	}
+	if rtCmd, err := LookupRtCommand("", name); err == nil {
+		return rtCmd.Name, nil
+	}
+	return "", fmt.Errorf("unknown section %q", key)
+}
+
+// configEnvName returns the environment variable of a setting such as
+// build_name or build-name.
+func configEnvName(key string) string {
+	return "PLUGIN_" + strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), "-", "_"))
+}
+
+// configFields returns the fields of Args keyed by environment variable.
+func configFields() map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	t := reflect.TypeOf(Args{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Tag.Get("envconfig"); strings.HasPrefix(name, "PLUGIN_") && name != ConfigFileEnv {
+			fields[name] = field
+		}
+	}
+	return fields
+}
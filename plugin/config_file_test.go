@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+const testConfigYAML = `
+url: https://artifactory.test.io/artifactory/
+build_name: t2
+build-number: v1.0
+threads: 4
+commands: [upload, publish-build-info]
+upload:
+  source: dist/*.jar
+  target: libs-release-local/app/
+maven:
+  goals: clean deploy
+promote:
+  target: libs-release
+`
+
+func TestParseConfigFile(t *testing.T) {
+	for _, name := range []string{"PLUGIN_BUILD_TOOL", "PLUGIN_COMMAND", "PLUGIN_COMMANDS"} {
+		unsetEnv(t, name)
+	}
+
+	got, err := parseConfigFile([]byte(testConfigYAML))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"PLUGIN_URL":          "https://artifactory.test.io/artifactory/",
+		"PLUGIN_BUILD_NAME":   "t2",
+		"PLUGIN_BUILD_NUMBER": "v1.0",
+		"PLUGIN_THREADS":      "4",
+		"PLUGIN_COMMANDS":     "upload,publish-build-info",
+		"PLUGIN_SOURCE":       "dist/*.jar",
+		"PLUGIN_TARGET":       "libs-release-local/app/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected settings:\n%v\nGot:\n%v", want, got)
+	}
+}
+
+func TestParseConfigFileSelectsBuildToolSection(t *testing.T) {
+	for _, name := range []string{"PLUGIN_COMMAND", "PLUGIN_COMMANDS"} {
+		unsetEnv(t, name)
+	}
+	t.Setenv("PLUGIN_BUILD_TOOL", "maven")
+
+	got, err := parseConfigFile([]byte(`{"url": "https://a.io/", "maven": {"goals": "clean install"}, "upload": {"source": "a"}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{"PLUGIN_URL": "https://a.io/", "PLUGIN_GOALS": "clean install"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected settings:\n%v\nGot:\n%v", want, got)
+	}
+}
+
+func TestParseConfigFileKeepsScalarsVerbatim(t *testing.T) {
+	for _, name := range []string{"PLUGIN_BUILD_TOOL", "PLUGIN_COMMAND", "PLUGIN_COMMANDS"} {
+		unsetEnv(t, name)
+	}
+
+	got, err := parseConfigFile([]byte(`
+build_number: 010
+build_name: 2.0
+threads: 0x10
+exclude_builds: 1.10
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"PLUGIN_BUILD_NUMBER":   "010",
+		"PLUGIN_BUILD_NAME":     "2.0",
+		"PLUGIN_THREADS":        "0x10",
+		"PLUGIN_EXCLUDE_BUILDS": "1.10",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected settings:\n%v\nGot:\n%v", want, got)
+	}
+}
+
+func TestParseConfigFileEmptyEnvIsUnset(t *testing.T) {
+	unsetEnv(t, "PLUGIN_COMMANDS")
+	t.Setenv("PLUGIN_BUILD_TOOL", "")
+	t.Setenv("PLUGIN_COMMAND", "")
+
+	got, err := parseConfigFile([]byte(`{"build_tool": "maven", "maven": {"goals": "clean install"}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got["PLUGIN_GOALS"] != "clean install" {
+		t.Errorf("Expected the maven section to apply, got %v", got)
+	}
+}
+
+func TestParseConfigFileConflictingSections(t *testing.T) {
+	t.Setenv("PLUGIN_COMMANDS", "upload,promote")
+	_, err := parseConfigFile([]byte(testConfigYAML))
+	want := "PLUGIN_TARGET is set differently in sections promote and upload"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error containing %q, got %v", want, err)
+	}
+}
+
+func TestParseConfigFileInvalid(t *testing.T) {
+	_, err := parseConfigFile([]byte(`
+urll: https://a.io/
+source: [a, b]
+deploy:
+  target: x
+promote:
+  target: {repo: x}
+`))
+	if err == nil {
+		t.Fatalf("Expected validation error")
+	}
+	for _, want := range []string{`unknown setting "urll"`, `setting "source" must not be a list`,
+		`unknown section "deploy"`, `promote: setting "target" must not be a map`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadConfigFileEnvWins(t *testing.T) {
+	for _, name := range []string{"PLUGIN_BUILD_TOOL", "PLUGIN_COMMAND", "PLUGIN_COMMANDS",
+		"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER", "PLUGIN_THREADS", "PLUGIN_SOURCE", "PLUGIN_TARGET"} {
+		unsetEnv(t, name)
+	}
+	t.Setenv("PLUGIN_BUILD_NAME", "from-env")
+	t.Setenv("PLUGIN_BUILD_NUMBER", "")
+
+	path := filepath.Join(t.TempDir(), "artifactory.yml")
+	if err := os.WriteFile(path, []byte(testConfigYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfigFile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var args Args
+	if err := envconfig.Process("", &args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.BuildName != "from-env" {
+		t.Errorf("Expected the environment to win, got build name %q", args.BuildName)
+	}
+	if args.BuildNumber != "v1.0" {
+		t.Errorf("Expected an empty variable to be set from the config file, got build number %q", args.BuildNumber)
+	}
+	if args.URL != RtUrlTestStr || args.Threads != 4 || args.Source != "dist/*.jar" {
+		t.Errorf("Expected settings from the config file, got url %q, threads %d, source %q",
+			args.URL, args.Threads, args.Source)
+	}
+	if !reflect.DeepEqual(args.Commands, []string{"upload", "publish-build-info"}) {
+		t.Errorf("Expected commands from the config file, got %q", args.Commands)
+	}
+}
+
+// unsetEnv unsets name for the duration of the test.
+func unsetEnv(t *testing.T, name string) {
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+}
@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+const dockerConfigEnv = "DOCKER_CONFIG"
+
+// dockerCommands are the plugin commands running jf docker.
+var dockerCommands = []string{"docker-push", "docker-pull"}
+
+// DockerOptions are the settings of jf docker push and jf docker pull.
+type DockerOptions struct {
+	Image           string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	SkipLogin       bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+	Threads         int    `split_words:"true"`
+}
+
+// NewDockerOptions returns the docker options of args, overridden by the
+// PLUGIN_DOCKER_ environment variables.
+func NewDockerOptions(args Args) (DockerOptions, error) {
+	opts := DockerOptions{
+		Image:           args.DockerImage,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		SkipLogin:       args.SkipLogin,
+		DetailedSummary: args.DetailedSummary,
+		Threads:         args.Threads,
+	}
+	return opts, loadOptions(DockerOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the docker options.
+func (o DockerOptions) Validate() []error {
+	problems := notNegative("PLUGIN_THREADS", o.Threads)
+	if o.Image == "" {
+		problems = append(problems, errors.New("PLUGIN_IMAGE needs to be set"))
+	}
+	return problems
+}
+
+// Flags returns the flags of jf docker push and pull besides the build
+// info flags.
+func (o DockerOptions) Flags() []string {
+	var flags cmdFlags
+	flags.addBool("--detailed-summary", o.DetailedSummary)
+	flags.addBool("--skip-login", o.SkipLogin)
+	flags.addInt("--threads", o.Threads)
+	return flags
+}
+
+// prepareDockerConfig gives the docker commands of the run a docker config
+// of their own, so that the registry login jf performs with the credentials
+// of the step is not stored in the docker config of the environment. Steps
+// skipping the login, or running no docker command, use the docker config of
+// the environment.
+func prepareDockerConfig(args *Args) error {
+	if args.workDir == "" || !runsDockerCommand(*args) {
+		return nil
+	}
+	opts, err := NewDockerOptions(*args)
+	// invalid settings are reported by the docker commands
+	if err != nil || opts.SkipLogin {
+		return nil
+	}
+
+	dir := filepath.Join(args.workDir, "docker")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating docker config: %s", err)
+	}
+	args.dockerConfigDir = dir
+	return nil
+}
+
+// runsDockerCommand reports whether one of the commands of the step runs
+// jf docker.
+func runsDockerCommand(args Args) bool {
+	for _, rtCmd := range stepRtCommands(args) {
+		if slices.Contains(dockerCommands, rtCmd.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func GetDockerPushCommandArgs(args Args) ([][]string, error) {
+	opts, err := NewDockerOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	return getDockerCommandArgs(args, opts, "push", opts.DeployerId)
+}
+
+func GetDockerPullCommandArgs(args Args) ([][]string, error) {
+	opts, err := NewDockerOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	return getDockerCommandArgs(args, opts, "pull", opts.ResolverId)
+}
+
+// getDockerCommandArgs returns the commands running jf docker with the
+// server id, which jf logs in to the registry of the image with, and
+// publishing the build info of the image when the build name and number are
+// set.
+func getDockerCommandArgs(args Args, opts DockerOptions, dockerCmd, id string) ([][]string, error) {
+
+	var cmdList [][]string
+
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, id)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	dockerCommandArgs := []string{DockerCmd, dockerCmd, opts.Image, "--server-id=" + serverId}
+	dockerCommandArgs = append(dockerCommandArgs, moduleBuildFlags(args)...)
+	dockerCommandArgs = append(dockerCommandArgs, opts.Flags()...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, dockerCommandArgs)
+
+	if args.BuildName == "" || args.BuildNumber == "" {
+		return cmdList, nil
+	}
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, buildDiscardCmd)
+	}
+
+	return cmdList, nil
+}
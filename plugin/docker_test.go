@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetDockerCommandArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		args    Args
+		want    []string
+	}{
+		{
+			command: "docker-push",
+			args: Args{DockerImage: "acme.jfrog.io/docker-local/app:1.0", DeployerId: RtDeployerId,
+				BuildName: RtBuildName, BuildNumber: RtBuildNumber, Threads: 2},
+			want: []string{
+				"config add " + RtDeployerId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+				"docker push acme.jfrog.io/docker-local/app:1.0 --server-id=" + RtDeployerId +
+					" --build-name=t2 --build-number=v1.0 --threads=2",
+				"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+			},
+		},
+		{
+			command: "docker-pull",
+			args:    Args{DockerImage: "acme.jfrog.io/docker/alpine:3", Module: "base", SkipLogin: true},
+			want: []string{
+				"config add tmpServerId --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+				"docker pull acme.jfrog.io/docker/alpine:3 --server-id=tmpServerId --module=base --skip-login=true",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.command, func(t *testing.T) {
+			args := tc.args
+			args.Command = tc.command
+			args.AccessToken = RtAccessToken
+			args.URL = RtUrlTestStr
+
+			cmdList, err := GetRtCommandsList(args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(cmdList) != len(tc.want) {
+				t.Fatalf("Expected %d commands, got %d: %v", len(tc.want), len(cmdList), cmdList)
+			}
+			for i, cmd := range cmdList {
+				if got := commandLine(cmd); got != tc.want[i] {
+					t.Errorf("Expected: %s, Got: %s", tc.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDockerCommandArgsMissingImage(t *testing.T) {
+	_, err := GetDockerPushCommandArgs(Args{URL: RtUrlTestStr, AccessToken: RtAccessToken})
+	want := "PLUGIN_IMAGE needs to be set"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+}
+
+func TestPrepareDockerConfig(t *testing.T) {
+	args := Args{Commands: []string{"docker-pull", "upload"}, workDir: t.TempDir()}
+	if err := prepareDockerConfig(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.dockerConfigDir == "" {
+		t.Fatalf("Expected a docker config in the run directory")
+	}
+	want := dockerConfigEnv + "=" + args.dockerConfigDir
+	if !slices.Contains(commandEnv(args, []string{"jf", DockerCmd, "pull"}), want) {
+		t.Errorf("Expected %s in the environment of jf docker", want)
+	}
+	if slices.Contains(commandEnv(args, []string{"jf", "rt", "u"}), want) {
+		t.Errorf("Expected no %s in the environment of other commands", dockerConfigEnv)
+	}
+
+	args = Args{Command: "upload", workDir: t.TempDir()}
+	if err := prepareDockerConfig(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.dockerConfigDir != "" {
+		t.Errorf("Expected no docker config without a docker command, got %q", args.dockerConfigDir)
+	}
+
+	t.Setenv("PLUGIN_DOCKER_SKIP_LOGIN", "true")
+	args = Args{Command: "docker-push", workDir: t.TempDir()}
+	if err := prepareDockerConfig(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.dockerConfigDir != "" {
+		t.Errorf("Expected the docker config of the environment when skipping the login, got %q", args.dockerConfigDir)
+	}
+}
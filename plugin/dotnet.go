@@ -0,0 +1,214 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultNupkgPattern matches the packages dotnet pack writes to the bin
+// directories of the projects, leaving out the restored packages of the
+// working directory.
+const defaultNupkgPattern = "**/bin/**/*.nupkg"
+
+// DotnetOptions are the settings of the dotnet and nuget commands.
+type DotnetOptions struct {
+	Args            string `split_words:"true"`
+	Solution        string `split_words:"true"`
+	NupkgPattern    string `split_words:"true"`
+	RepoResolve     string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	ServerIdResolve string `split_words:"true"`
+	NugetV2         *bool  `split_words:"true"`
+	Global          bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+	Threads         int    `split_words:"true"`
+}
+
+// NewDotnetOptions returns the dotnet options of args, overridden by the
+// PLUGIN_DOTNET_ environment variables.
+func NewDotnetOptions(args Args) (DotnetOptions, error) {
+	opts := DotnetOptions{
+		Args:            args.DotnetArgs,
+		Solution:        args.Solution,
+		NupkgPattern:    args.NupkgPattern,
+		RepoResolve:     args.RepoResolve,
+		RepoDeploy:      args.RepoDeploy,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		ServerIdResolve: args.ServerIdResolve,
+		NugetV2:         args.NugetV2,
+		Global:          args.Global,
+		DetailedSummary: args.DetailedSummary,
+		Threads:         args.Threads,
+	}
+	err := loadOptions(DotnetOptionsPrefix, &opts)
+	opts.NupkgPattern = valueOrDefault(opts.NupkgPattern, defaultNupkgPattern)
+	return opts, err
+}
+
+// Validate returns the problems of the dotnet options.
+func (o DotnetOptions) Validate() []error {
+	return notNegative("PLUGIN_THREADS", o.Threads)
+}
+
+// ValidateBuild returns the problems of the dotnet options for restoring
+// and building the solution.
+func (o DotnetOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if o.RepoResolve == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_RESOLVE needs to be set"))
+	}
+	return problems
+}
+
+// ValidatePublish returns the problems of the dotnet options for uploading
+// the packages.
+func (o DotnetOptions) ValidatePublish() []error {
+	problems := o.Validate()
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	return problems
+}
+
+// setNonInteractiveDefaults fills in the dotnet-config and nuget-config
+// settings jf would otherwise prompt for.
+func (o *DotnetOptions) setNonInteractiveDefaults() {
+	o.Global = true
+	if o.NugetV2 == nil {
+		nugetV2 := false
+		o.NugetV2 = &nugetV2
+	}
+}
+
+// ConfigFlags returns the flags of jf dotnet-config and nuget-config.
+func (o DotnetOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addOptionalBool("--nuget-v2", o.NugetV2)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	return flags
+}
+
+// dotnetBuildCommand returns the builder restoring the packages of a .NET
+// solution with tool.
+func dotnetBuildCommand(tool string) RtCommandBuilder {
+	return jfBuilder(func(args Args) ([][]string, error) {
+		return GetDotnetBuildCommandArgs(tool, args)
+	})
+}
+
+func GetDotnetBuildCommandArgs(tool string, args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewDotnetOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	// Add necessary parameters for Windows to prevent all interactive prompts
+	if runtime.GOOS == "windows" {
+		opts.setNonInteractiveDefaults()
+	}
+	dotnetConfigCommandArgs := append([]string{tool + "-config"}, opts.ConfigFlags()...)
+
+	// the dependencies of the build info are collected when restoring
+	dotnetRestoreCommandArgs := []string{tool, "restore"}
+	if opts.Solution != "" {
+		dotnetRestoreCommandArgs = append(dotnetRestoreCommandArgs, opts.Solution)
+	}
+	dotnetRestoreCommandArgs = append(dotnetRestoreCommandArgs, moduleBuildFlags(args)...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, dotnetConfigCommandArgs)
+	cmdList = append(cmdList, dotnetRestoreCommandArgs)
+
+	// nuget only restores, the solution is built by msbuild
+	if tool == DotnetCmd {
+		dotnetBuildCommandArgs := []string{tool, "build"}
+		if opts.Solution != "" {
+			dotnetBuildCommandArgs = append(dotnetBuildCommandArgs, opts.Solution)
+		}
+		dotnetBuildCommandArgs = append(dotnetBuildCommandArgs, "--no-restore")
+		dotnetArgs, err := splitArgs(opts.Args)
+		if err != nil {
+			return cmdList, fmt.Errorf("invalid PLUGIN_DOTNET_ARGS: %w", err)
+		}
+		dotnetBuildCommandArgs = append(dotnetBuildCommandArgs, dotnetArgs...)
+		cmdList = append(cmdList, dotnetBuildCommandArgs)
+	}
+
+	return cmdList, nil
+}
+
+func GetDotnetPublishCommand(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewDotnetOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidatePublish()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	// upload the packages to the root of the deploy repository, where
+	// Artifactory indexes them
+	rtUploadCommandArgs := []string{"rt", "u", opts.NupkgPattern,
+		strings.TrimSuffix(opts.RepoDeploy, "/") + "/", "--server-id=" + serverId, "--flat=true"}
+	rtUploadCommandArgs = append(rtUploadCommandArgs, moduleBuildFlags(args)...)
+	var uploadFlags cmdFlags
+	uploadFlags.addInt("--threads", opts.Threads)
+	uploadFlags.addBool("--detailed-summary", opts.DetailedSummary)
+	rtUploadCommandArgs = append(rtUploadCommandArgs, uploadFlags...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, rtUploadCommandArgs)
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, buildDiscardCmd)
+	}
+
+	return cmdList, nil
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetDotnetBuildCommandArgs(t *testing.T) {
+	tests := []struct {
+		buildTool string
+		args      Args
+		want      []string
+	}{
+		{
+			buildTool: "dotnet",
+			args:      Args{RepoResolve: "nuget-virtual", Solution: "App.sln", DotnetArgs: "-c Release"},
+			want: []string{
+				"config add tmpServerId --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+				"dotnet-config --repo-resolve=nuget-virtual --server-id-resolve=tmpServerId",
+				"dotnet restore App.sln --build-name=t2 --build-number=v1.0",
+				"dotnet build App.sln --no-restore -c Release",
+			},
+		},
+		{
+			buildTool: "nuget",
+			args:      Args{RepoResolve: "nuget-virtual", ResolverId: RtRslvId, Module: "app"},
+			want: []string{
+				"config add " + RtRslvId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+				"nuget-config --repo-resolve=nuget-virtual --server-id-resolve=" + RtRslvId,
+				"nuget restore --build-name=t2 --build-number=v1.0 --module=app",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.buildTool, func(t *testing.T) {
+			args := tc.args
+			args.BuildTool = tc.buildTool
+			args.AccessToken = RtAccessToken
+			args.URL = RtUrlTestStr
+			args.BuildName = RtBuildName
+			args.BuildNumber = RtBuildNumber
+
+			cmdList, err := GetDotnetBuildCommandArgs(tc.buildTool, args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(cmdList) != len(tc.want) {
+				t.Fatalf("Expected %d commands, got %d: %v", len(tc.want), len(cmdList), cmdList)
+			}
+			for i, cmd := range cmdList {
+				if got := strings.Join(cmd, " "); got != tc.want[i] {
+					t.Errorf("Expected: %s, Got: %s", tc.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDotnetPublishCommand(t *testing.T) {
+	args := Args{
+		BuildTool:       "dotnet",
+		Command:         "publish",
+		Username:        "ab",
+		Password:        "cd",
+		URL:             RtUrlTestStr,
+		RepoDeploy:      "nuget-local",
+		DeployerId:      RtDeployerId,
+		NupkgPattern:    "bin/Release/*.nupkg",
+		BuildName:       RtBuildName,
+		BuildNumber:     RtBuildNumber,
+		DetailedSummary: true,
+	}
+	cmdList, err := GetDotnetPublishCommand(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
+			" --user=ab --password-stdin --interactive=false",
+		"rt u bin/Release/*.nupkg nuget-local/ --server-id=" + RtDeployerId +
+			" --flat=true --build-name=t2 --build-number=v1.0 --detailed-summary=true",
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := strings.Join(cmd, " "); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestDotnetOptionsNonInteractiveDefaults(t *testing.T) {
+	t.Setenv("PLUGIN_DOTNET_REPO_RESOLVE", "nuget-remote")
+
+	opts, err := NewDotnetOptions(Args{RepoResolve: "nuget-virtual", ServerIdResolve: RtRslvId})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.NupkgPattern != defaultNupkgPattern {
+		t.Errorf("Expected the default package pattern, got %q", opts.NupkgPattern)
+	}
+	opts.setNonInteractiveDefaults()
+
+	want := "--global=true --nuget-v2=false --repo-resolve=nuget-remote --server-id-resolve=" + RtRslvId
+	if got := strings.Join(opts.ConfigFlags(), " "); got != want {
+		t.Errorf("Expected: %s, Got: %s", want, got)
+	}
+	if problems := opts.ValidatePublish(); len(problems) != 1 || problems[0].Error() != "PLUGIN_REPO_DEPLOY needs to be set" {
+		t.Errorf("Expected a missing deploy repo, got %v", problems)
+	}
+}
@@ -0,0 +1,227 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandWaitDelay is how long a cancelled command is given to exit after
+// SIGTERM before it is killed.
+const commandWaitDelay = 10 * time.Second
+
+// maxCapturedOutput bounds the output of a command kept in its ExecResult.
+// jf prints its summaries last, so only the tail of long builds is kept.
+const maxCapturedOutput = 1 << 20
+
+// secretEnvVars are the environment variables holding the credentials of
+// the plugin.
+var secretEnvVars = []string{
+	"PLUGIN_USERNAME",
+	"PLUGIN_PASSWORD",
+	"PLUGIN_API_KEY",
+	"PLUGIN_ACCESS_TOKEN",
+}
+
+// Flags of jf config add reading the credential from standard input, which
+// keeps it out of the arguments visible to other processes.
+const (
+	passwordStdinFlag    = "--password-stdin"
+	accessTokenStdinFlag = "--access-token-stdin"
+)
+
+// gradlePasswordEnv passes the password to the Gradle build as the password
+// project property.
+const gradlePasswordEnv = "ORG_GRADLE_PROJECT_password"
+
+// ExecRequest describes a command run by an Executor.
+type ExecRequest struct {
+	// Argv holds the program to run and its arguments.
+	Argv []string
+	// Env is the complete environment of the child process.
+	Env []string
+	// Stdin is written to the standard input of the command.
+	Stdin string
+	// Dir is the working directory of the command, the working directory of
+	// the plugin when empty.
+	Dir string
+}
+
+// ExecResult holds the outcome of a command run by an Executor.
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// Executor runs a single command.
+type Executor interface {
+	Run(ctx context.Context, req ExecRequest) (ExecResult, error)
+}
+
+// OSExecutor runs commands as child processes of the plugin. Output is
+// streamed to Stdout and Stderr while its last maxCapturedOutput bytes are
+// captured in the ExecResult.
+type OSExecutor struct {
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// NewOSExecutor returns an OSExecutor streaming to the plugin output.
+func NewOSExecutor() *OSExecutor {
+	return &OSExecutor{Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+// Run executes the command of req and waits for it to complete.
+func (e *OSExecutor) Run(ctx context.Context, req ExecRequest) (ExecResult, error) {
+	stdout := &tailBuffer{max: maxCapturedOutput}
+	stderr := &tailBuffer{max: maxCapturedOutput}
+
+	cmd := exec.CommandContext(ctx, req.Argv[0], req.Argv[1:]...)
+	configureCancel(cmd)
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Env = req.Env
+	cmd.Dir = req.Dir
+	if req.Stdin != "" {
+		cmd.Stdin = strings.NewReader(req.Stdin)
+	}
+	cmd.Stdout = io.MultiWriter(e.Stdout, stdout)
+	cmd.Stderr = io.MultiWriter(e.Stderr, stderr)
+
+	err := cmd.Run()
+	flushOutput(e.Stdout)
+	flushOutput(e.Stderr)
+
+	result := ExecResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	return result, err
+}
+
+// tailBuffer is a writer keeping only the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(p) >= b.max {
+		b.buf = append(b.buf[:0], p[len(p)-b.max:]...)
+		return n, nil
+	}
+	if drop := len(b.buf) + len(p) - b.max; drop > 0 {
+		b.buf = append(b.buf[:0], b.buf[drop:]...)
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
+}
+
+// runCommand runs the jf command described by cmdArgs. cmdArgs[0] is the
+// program, the remaining entries are passed as separate arguments unless
+// args.LegacyShellExec is set, in which case the command line is run through
+// the platform shell as in earlier versions of the plugin. Credentials are
+// never part of the arguments, jf config add reads them from standard input.
+// The command runs in dir, the working directory of the plugin when empty.
+//
+// The command is stopped when ctx is done or after args.CommandTimeout.
+func runCommand(ctx context.Context, executor Executor, args Args, cmdArgs []string, dir string) (ExecResult, error) {
+	env := commandEnv(args, cmdArgs)
+	if jfSubcommand(cmdArgs) == GradleCmd && args.Password != "" {
+		env = append(env, gradlePasswordEnv+"="+args.Password)
+	}
+	redactor := NewRedactor(args)
+
+	cmdCtx := ctx
+	if args.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, args.CommandTimeout)
+		defer cancel()
+	}
+
+	argv := cmdArgs
+	if args.LegacyShellExec {
+		shell, shArg := getShell()
+		argv = []string{shell, shArg, shellJoin(cmdArgs)}
+	}
+	trace(redactor, argv)
+
+	req := ExecRequest{Argv: argv, Env: env, Stdin: commandStdin(args, cmdArgs), Dir: dir}
+	result, err := executor.Run(cmdCtx, req)
+	if err != nil {
+		err = contextError(ctx, cmdCtx, args, cmdArgs, err)
+	}
+	return result, err
+}
+
+// contextError explains why a command failed when it was stopped by a
+// timeout or a cancelled pipeline, and returns err otherwise.
+func contextError(ctx, cmdCtx context.Context, args Args, cmdArgs []string, err error) error {
+	cmdStr := strings.Join(cmdArgs, " ")
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("command %q timed out, plugin timeout of %s exceeded: %w", cmdStr, args.Timeout, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("command %q cancelled: %w", cmdStr, err)
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("command %q timed out after %s: %w", cmdStr, args.CommandTimeout, err)
+	}
+	return err
+}
+
+// commandEnv returns the environment passed to the jf invocation cmdArgs.
+// The docker config of the run is only passed to jf docker.
+func commandEnv(args Args, cmdArgs []string) []string {
+	env := os.Environ()
+	env = append(env, "JFROG_CLI_OFFER_CONFIG=false")
+	if args.cliHomeDir != "" {
+		env = append(env, jfrogCliHomeDirEnv+"="+args.cliHomeDir)
+	}
+	if args.dockerConfigDir != "" && jfSubcommand(cmdArgs) == DockerCmd {
+		env = append(env, dockerConfigEnv+"="+args.dockerConfigDir)
+	}
+	if args.npmrcPath != "" {
+		env = append(env, npmrcEnv+"="+args.npmrcPath)
+	}
+	return env
+}
+
+// commandStdin returns the credential read from standard input by cmdArgs,
+// empty when the command reads none.
+func commandStdin(args Args, cmdArgs []string) string {
+	for _, arg := range cmdArgs {
+		if arg == passwordStdinFlag || arg == accessTokenStdinFlag {
+			return authSecret(args)
+		}
+	}
+	return ""
+}
+
+// shellJoin joins argv into a command line for the platform shell, quoting
+// the arguments containing whitespace or quotes.
+func shellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"") {
+			quoted[i] = arg
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
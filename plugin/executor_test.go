@@ -0,0 +1,428 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingExecutor is a fake Executor recording every command it is asked
+// to run. Commands containing failOn return an error with exit code 1.
+type recordingExecutor struct {
+	commands []string
+	argvs    [][]string
+	stdins   []string
+	failOn   string
+}
+
+func (e *recordingExecutor) Run(ctx context.Context, req ExecRequest) (ExecResult, error) {
+	cmdStr := strings.Join(req.Argv, " ")
+	e.commands = append(e.commands, cmdStr)
+	e.argvs = append(e.argvs, req.Argv)
+	e.stdins = append(e.stdins, req.Stdin)
+	if e.failOn != "" && strings.Contains(cmdStr, e.failOn) {
+		return ExecResult{ExitCode: 1, Stderr: "failed"}, errors.New("exit status 1")
+	}
+	return ExecResult{}, nil
+}
+
+// executorFunc adapts a function to the Executor interface.
+// executorFunc adapts a function receiving the argv and the environment of
+// a command to the Executor interface.
+type executorFunc func(ctx context.Context, argv []string, env []string) (ExecResult, error)
+
+func (f executorFunc) Run(ctx context.Context, req ExecRequest) (ExecResult, error) {
+	return f(ctx, req.Argv, req.Env)
+}
+
+// requestFunc is an Executor receiving the whole ExecRequest of a command.
+type requestFunc func(ctx context.Context, req ExecRequest) (ExecResult, error)
+
+func (f requestFunc) Run(ctx context.Context, req ExecRequest) (ExecResult, error) {
+	return f(ctx, req)
+}
+
+func TestHandleRtCommandsMvnPublishWithBuildDiscard(t *testing.T) {
+	args := Args{
+		BuildTool:   "mvn",
+		Command:     "publish",
+		Username:    "ab0",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		DeployerId:  RtDeployerId,
+		MaxBuilds:   "5",
+	}
+	executor := &recordingExecutor{}
+	if err := HandleRtCommands(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"jf config add " + RtDeployerId,
+		"jf mvn-config",
+		"jf mvn deploy --build-name=t2 --build-number=v1.0",
+		"jf rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+		"jf rt build-discard --max-builds=5 --server-id=" + RtDeployerId + " t2",
+	}
+	if len(executor.commands) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(executor.commands), executor.commands)
+	}
+	for i, want := range wantCmds {
+		if !strings.HasPrefix(executor.commands[i], want) {
+			t.Errorf("Command mismatch at index %d:\nExpected prefix: %q\nGot:             %q",
+				i, want, executor.commands[i])
+		}
+	}
+}
+
+func TestHandleRtCommandsStopsOnFailure(t *testing.T) {
+	args := Args{
+		BuildTool:   "mvn",
+		Command:     "publish",
+		Username:    "ab0",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		MaxBuilds:   "5",
+	}
+	executor := &recordingExecutor{failOn: "build-publish"}
+	if err := HandleRtCommands(context.Background(), args, executor); err == nil {
+		t.Fatalf("Expected error from failing build-publish")
+	}
+
+	if len(executor.commands) != 4 {
+		t.Fatalf("Expected execution to stop after 4 commands, got %d: %v",
+			len(executor.commands), executor.commands)
+	}
+	for _, cmd := range executor.commands {
+		if strings.Contains(cmd, "build-discard") {
+			t.Errorf("Expected build-discard not to run after failure, got %q", cmd)
+		}
+	}
+}
+
+func TestExecUploadPublishesBuildInfo(t *testing.T) {
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/app.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"jf config add tmpServerId-",
+		"jf rt u --server-id=tmpServerId-",
+		"jf config add tmpServerIdbpi-",
+		"jf rt build-publish t2 v1.0 --server-id=tmpServerIdbpi-",
+	}
+	if len(executor.commands) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(executor.commands), executor.commands)
+	}
+	for i, want := range wantCmds {
+		if !strings.HasPrefix(executor.commands[i], want) {
+			t.Errorf("Command mismatch at index %d:\nExpected prefix: %q\nGot:             %q",
+				i, want, executor.commands[i])
+		}
+	}
+}
+
+func TestExecUploadFailureSkipsBuildInfo(t *testing.T) {
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/app.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+	executor := &recordingExecutor{failOn: "rt u"}
+	if err := ExecWithExecutor(context.Background(), args, executor); err == nil {
+		t.Fatalf("Expected error from failing upload")
+	}
+	if len(executor.commands) != 2 {
+		t.Errorf("Expected only the upload to run, got %v", executor.commands)
+	}
+}
+
+func TestExecUploadPassesArgvWithoutShell(t *testing.T) {
+	args := Args{
+		Username:    "ab",
+		Password:    "it's a secret",
+		URL:         RtUrlTestStr,
+		Source:      "dist/*.jar",
+		Target:      "libs-release-local/app/",
+		BuildName:   "my build",
+		BuildNumber: RtBuildNumber,
+		TargetProps: "team=\"core services\"",
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"jf", "rt", "u", "--server-id=" + executor.argvs[0][3],
+		"--flat=false", "--build-number=v1.0", "--build-name=my build", "--target-props=team=\"core services\"",
+		"dist/*.jar", "libs-release-local/app/"}
+	if !reflect.DeepEqual(executor.argvs[1], want) {
+		t.Errorf("Expected argv: %q\nGot: %q", want, executor.argvs[1])
+	}
+}
+
+func TestExecPassesCredentialsOnStdin(t *testing.T) {
+	args := Args{
+		Username:         "ab",
+		Password:         "it's a secret",
+		URL:              RtUrlTestStr,
+		Source:           "dist/*.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, cmd := range executor.commands {
+		if strings.Contains(cmd, args.Password) {
+			t.Errorf("Expected the password to be kept out of the arguments, got %q", cmd)
+		}
+		wantStdin := ""
+		if strings.HasPrefix(cmd, "jf config add ") {
+			wantStdin = args.Password
+		}
+		if executor.stdins[i] != wantStdin {
+			t.Errorf("Expected stdin %q for %q, got %q", wantStdin, cmd, executor.stdins[i])
+		}
+	}
+}
+
+func TestExecUploadLegacyShellExec(t *testing.T) {
+	args := Args{
+		AccessToken:     RtAccessToken,
+		URL:             RtUrlTestStr,
+		Source:          "dist/*.jar",
+		Target:          "libs-release-local/app/",
+		BuildName:       "my build",
+		BuildNumber:     RtBuildNumber,
+		LegacyShellExec: true,
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	shell, shArg := getShell()
+	argv := executor.argvs[1]
+	if len(argv) != 3 || argv[0] != shell || argv[1] != shArg {
+		t.Fatalf("Expected command to run through %s %s, got %q", shell, shArg, argv)
+	}
+	want := "jf rt u --server-id=" + serverID(Args{runID: strings.TrimPrefix(argv[2], "jf rt u --server-id=tmpServerId-")[:8]}, tmpServerId) +
+		" --flat=false --build-number=v1.0 '--build-name=my build' dist/*.jar libs-release-local/app/"
+	if argv[2] != want {
+		t.Errorf("Expected: %s\nGot: %s", want, argv[2])
+	}
+	if executor.stdins[0] != RtAccessToken {
+		t.Errorf("Expected the access token to be passed on stdin to the shell")
+	}
+}
+
+func TestRunCommandTimeoutStopsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are only signalled on unix")
+	}
+	args := Args{CommandTimeout: 200 * time.Millisecond}
+	executor := &OSExecutor{Stdout: io.Discard, Stderr: io.Discard}
+
+	// the shell waits for its child, which only stops when the whole group
+	// receives SIGTERM
+	start := time.Now()
+	_, err := runCommand(context.Background(), executor, args, []string{"sh", "-c", "sleep 30; true"}, "")
+	if err == nil {
+		t.Fatalf("Expected timeout error")
+	}
+	if !strings.Contains(err.Error(), `command "sh -c sleep 30; true" timed out after 200ms`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the command to stop promptly, took %s", elapsed)
+	}
+}
+
+func TestHandleRtCommandsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	args := Args{
+		Command:     "publish-build-info",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	var ran int
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		ran++
+		cancel()
+		return ExecResult{ExitCode: -1}, errors.New("signal: terminated")
+	})
+	err := HandleRtCommands(ctx, args, executor)
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+	if ran != 1 {
+		t.Errorf("Expected no command to run after cancellation, ran %d", ran)
+	}
+}
+
+func TestHandleRtCommandsRunsCommandList(t *testing.T) {
+	args := Args{
+		Commands:    []string{"upload", "publish-build-info", "scan", "promote", "build-discard"},
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "dist/app.jar",
+		Target:      "libs-release-local/app/",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		MaxBuilds:   "5",
+	}
+	executor := &recordingExecutor{}
+	if err := HandleRtCommands(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"jf config add " + tmpServerId,
+		"jf rt u --server-id=" + tmpServerId,
+		"jf rt build-publish t2 v1.0",
+		"jf build-scan t2 v1.0 --server-id=" + tmpServerId,
+		"jf rt build-promote",
+		"jf rt build-discard --max-builds=5 --server-id=" + tmpServerId + " t2",
+	}
+	if len(executor.commands) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(executor.commands), executor.commands)
+	}
+	for i, want := range wantCmds {
+		if !strings.HasPrefix(executor.commands[i], want) {
+			t.Errorf("Command mismatch at index %d:\nExpected prefix: %q\nGot:             %q",
+				i, want, executor.commands[i])
+		}
+	}
+}
+
+func TestHandleRtCommandsCommandListStopsOnFailure(t *testing.T) {
+	args := Args{
+		Commands:    []string{"publish-build-info", "add-build-dependencies", "promote"},
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Target:      "libs-release",
+	}
+	executor := &recordingExecutor{failOn: "build-add-dependencies"}
+	if err := HandleRtCommands(context.Background(), args, executor); err == nil {
+		t.Fatalf("Expected error from failing add-build-dependencies")
+	}
+
+	var configAdds int
+	for _, cmd := range executor.commands {
+		if strings.HasPrefix(cmd, "jf config add") {
+			configAdds++
+		}
+		if strings.Contains(cmd, "build-promote") {
+			t.Errorf("Expected promote not to run after failure, got %q", cmd)
+		}
+	}
+	if configAdds != 1 {
+		t.Errorf("Expected the server to be registered once, got %d: %v", configAdds, executor.commands)
+	}
+}
+
+func TestGetRtCommandStepsValidatesAllCommands(t *testing.T) {
+	args := Args{
+		Commands:    []string{"publish-build-info", "promote"},
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	_, err := GetRtCommandSteps(args)
+	want := "command \"promote\": PLUGIN_TARGET needs to be set"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+}
+
+func TestTailBufferKeepsLastBytes(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	for _, s := range []string{"abc", "defgh", "ijk"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Unexpected write result %d, %v", n, err)
+		}
+	}
+	if got := b.String(); got != "defghijk" {
+		t.Errorf("Expected %q, got %q", "defghijk", got)
+	}
+	b.Write([]byte("0123456789"))
+	if got := b.String(); got != "23456789" {
+		t.Errorf("Expected %q, got %q", "23456789", got)
+	}
+}
+
+func TestOSExecutorRunsInDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	executor := &OSExecutor{Stdout: io.Discard, Stderr: io.Discard}
+	result, err := executor.Run(context.Background(), ExecRequest{Argv: []string{"sh", "-c", "pwd -P"}, Dir: dir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(result.Stdout); got != want {
+		t.Errorf("Expected the command to run in %s, got %s", want, got)
+	}
+}
+
+func TestOSExecutorStreamsAndCapturesTail(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	var console strings.Builder
+	executor := &OSExecutor{Stdout: &console, Stderr: io.Discard}
+	script := "i=0; while [ $i -lt 30000 ]; do echo line-$i-padding-padding-padding-padding; i=$((i+1)); done; echo summary"
+	result, err := executor.Run(context.Background(), ExecRequest{Argv: []string{"sh", "-c", script}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(console.String(), "line-0-") || !strings.HasSuffix(console.String(), "summary\n") {
+		t.Errorf("Expected the whole output to be streamed to the console")
+	}
+	if len(result.Stdout) > maxCapturedOutput {
+		t.Errorf("Expected at most %d captured bytes, got %d", maxCapturedOutput, len(result.Stdout))
+	}
+	if !strings.HasSuffix(result.Stdout, "summary\n") {
+		t.Errorf("Expected the captured output to end with the summary")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureCancel starts the command in its own process group and, when the
+// context is done, sends SIGTERM to the whole group so that the mvn, gradle
+// or other processes started by jf stop as well.
+func configureCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package plugin
+
+import (
+	"os/exec"
+)
+
+// configureCancel keeps the default behavior of killing the process when the
+// context is done, windows has no SIGTERM to forward.
+func configureCancel(cmd *exec.Cmd) {}
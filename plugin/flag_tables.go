@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// flagTables lists the flag tables rendered by PopulateArgs, keyed by name.
+// Every table must be listed here so that CheckFlagTables covers it.
+var flagTables = map[string][]JsonTagToExeFlagMapStringItem{
+	"RtBuildInfoPublish": RtBuildInfoPublishCmdJsonTagToExeFlagMap,
+	"DownloadCmd":        DownloadCmdJsonTagToExeFlagMapStringItemList,
+	"AddDependenciesCmd": AddDependenciesCmdJsonToExeFlagMapItemList,
+}
+
+// CheckFlagTables fails when a flag table references a setting that is not
+// a field of Args, or a field of a type PopulateArgs cannot render, which
+// would otherwise be dropped silently.
+func CheckFlagTables() error {
+	names := make([]string, 0, len(flagTables))
+	for name := range flagTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []error
+	for _, name := range names {
+		for _, item := range flagTables[name] {
+			if _, err := getFlagValue(&Args{}, item.PluginArgJsonTag); err != nil {
+				problems = append(problems, fmt.Errorf("flag table %s, flag %s: %s", name, item.FlagName, err))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
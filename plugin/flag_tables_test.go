@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckFlagTables(t *testing.T) {
+	if err := CheckFlagTables(); err != nil {
+		t.Fatalf("Expected every flag table to reference Args fields: %v", err)
+	}
+
+	flagTables["Broken"] = []JsonTagToExeFlagMapStringItem{
+		{"--missing=", "PLUGIN_MISSING", false, false},
+		{"--retry-patterns=", "PLUGIN_RETRY_PATTERNS", false, false},
+	}
+	defer delete(flagTables, "Broken")
+
+	err := CheckFlagTables()
+	if err == nil {
+		t.Fatalf("Expected an error for the broken table")
+	}
+	for _, want := range []string{
+		"flag table Broken, flag --missing=: field with tag 'PLUGIN_MISSING' not found",
+		"flag table Broken, flag --retry-patterns=: field with tag 'PLUGIN_RETRY_PATTERNS' has unsupported type []string",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestPopulateArgsTypedFields(t *testing.T) {
+	deployIvyDesc := false
+	args := Args{
+		BuildName:       RtBuildName,
+		Threads:         4,
+		DetailedSummary: true,
+		Scan:            false,
+		Format:          "json",
+		DeployIvyDesc:   &deployIvyDesc,
+	}
+	table := []JsonTagToExeFlagMapStringItem{
+		{"--build-name=", "PLUGIN_BUILD_NAME", false, false},
+		{"--threads=", "PLUGIN_THREADS", false, false},
+		{"--detailed-summary=", "PLUGIN_DETAILED_SUMMARY", false, false},
+		{"--scan=", "PLUGIN_SCAN", false, false},
+		{"--format=", "PLUGIN_FORMAT", false, false},
+		{"--deploy-ivy-desc=", "PLUGIN_DEPLOY_IVY_DESC", false, false},
+		{"--deploy-maven-desc=", "PLUGIN_DEPLOY_MAVEN_DESC", false, false},
+	}
+
+	cmdArgs := []string{"gradle"}
+	if err := PopulateArgs(&cmdArgs, &args, table); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "gradle --build-name=t2 --threads=4 --detailed-summary=true --format=json --deploy-ivy-desc=false"
+	if got := strings.Join(cmdArgs, " "); got != want {
+		t.Errorf("Expected: %s\nGot: %s", want, got)
+	}
+}
+
+func TestPopulateArgsMandatory(t *testing.T) {
+	cmdArgs := []string{"rt"}
+	table := []JsonTagToExeFlagMapStringItem{{"--build-name=", "PLUGIN_BUILD_NAME", true, false}}
+	if err := PopulateArgs(&cmdArgs, &Args{}, table); err == nil {
+		t.Errorf("Expected error for missing mandatory field")
+	}
+}
@@ -0,0 +1,169 @@
+package plugin
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const buildAddGit = "build-add-git"
+
+// emptyTreeHash is the git object id of the empty tree, referenced by the
+// commit written by prepareGitInfo.
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+var gitObjectIDPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// GetBuildAddGitCommandArgs returns the jf command recording the VCS details
+// of the checkout in the build info, read from the .git directory of the
+// working directory or from the one synthesized by prepareGitInfo.
+func GetBuildAddGitCommandArgs(args Args) []string {
+	cmdArgs := []string{"rt", buildAddGit, args.BuildName, args.BuildNumber}
+	if args.gitDir != "" {
+		cmdArgs = append(cmdArgs, args.gitDir)
+	}
+	return cmdArgs
+}
+
+// withGitInfo inserts the build-add-git command before every build-publish
+// of cmdList when PLUGIN_ADD_GIT_INFO is set.
+func withGitInfo(args Args, cmdList []Command) []Command {
+	if !args.AddGitInfo {
+		return cmdList
+	}
+	var result []Command
+	for _, cmd := range cmdList {
+		if cmd.isJfCommand(isBuildPublish) && !precededBy(result, isBuildAddGit) {
+			result = append(result, Command{Args: GetBuildAddGitCommandArgs(args)})
+		}
+		result = append(result, cmd)
+	}
+	return result
+}
+
+func isBuildPublish(cmdArgs []string) bool {
+	subCmd := jfSubcommand(cmdArgs)
+	return subCmd == "rt "+BuildPublish || subCmd == "rt bp"
+}
+
+func isBuildAddGit(cmdArgs []string) bool {
+	subCmd := jfSubcommand(cmdArgs)
+	return subCmd == "rt "+buildAddGit || subCmd == "rt bag"
+}
+
+// prepareGitInfo makes the VCS details of the pipeline available to
+// build-add-git when the working directory is not a git checkout, writing a
+// minimal .git directory with the commit, its message, the branch and the
+// remote of the pipeline to the run directory.
+func prepareGitInfo(args *Args) error {
+	if !args.AddGitInfo || args.workDir == "" {
+		return nil
+	}
+	if _, err := os.Stat(".git"); err == nil {
+		return nil
+	}
+	if args.Commit.Rev == "" {
+		logrus.Println("No .git directory and no commit in the pipeline metadata, build-add-git may not find VCS details")
+		return nil
+	}
+
+	dir := filepath.Join(args.workDir, "vcs")
+	dotGit := filepath.Join(dir, ".git")
+	branch := args.Commit.Branch
+	if branch == "" {
+		branch = args.Build.Branch
+	}
+
+	head := args.Commit.Rev + "\n"
+	if branch != "" {
+		head = "ref: refs/heads/" + branch + "\n"
+		ref := filepath.Join(dotGit, "refs", "heads", filepath.FromSlash(branch))
+		if err := writeGitFile(ref, args.Commit.Rev+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := writeGitFile(filepath.Join(dotGit, "HEAD"), head); err != nil {
+		return err
+	}
+	if err := writeGitCommit(dotGit, *args); err != nil {
+		return err
+	}
+
+	config := "[core]\n\trepositoryformatversion = 0\n\tbare = false\n"
+	if remote := gitRemoteURL(*args); remote != "" {
+		config += fmt.Sprintf("[remote \"origin\"]\n\turl = %s\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n", remote)
+	}
+	if err := writeGitFile(filepath.Join(dotGit, "config"), config); err != nil {
+		return err
+	}
+
+	args.gitDir = dir
+	return nil
+}
+
+// writeGitCommit writes the commit of the pipeline as a loose object named
+// after DRONE_COMMIT_SHA, so build-add-git reads its message. The commit has
+// an empty tree, the checkout itself is not recorded.
+func writeGitCommit(dotGit string, args Args) error {
+	rev := strings.ToLower(args.Commit.Rev)
+	if !gitObjectIDPattern.MatchString(rev) {
+		logrus.Printf("Commit %q is not a git object id, the commit message is not recorded\n", args.Commit.Rev)
+		return nil
+	}
+
+	author := args.Commit.Author.Name
+	if author == "" {
+		author = args.Commit.Author.Username
+	}
+	if author == "" {
+		author = "drone"
+	}
+	created := args.Build.Created
+	if created == 0 {
+		created = time.Now().Unix()
+	}
+	signature := fmt.Sprintf("%s <%s> %d +0000", author, args.Commit.Author.Email, created)
+	message := strings.TrimRight(args.Commit.Message, "\n") + "\n"
+	commit := fmt.Sprintf("tree %s\nauthor %s\ncommitter %s\n\n%s", emptyTreeHash, signature, signature, message)
+
+	if err := writeGitObject(dotGit, emptyTreeHash, "tree", ""); err != nil {
+		return err
+	}
+	return writeGitObject(dotGit, rev, "commit", commit)
+}
+
+func writeGitObject(dotGit, id, kind, content string) error {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	fmt.Fprintf(zw, "%s %d\x00%s", kind, len(content), content)
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error creating git metadata: %s", err)
+	}
+	return writeGitFile(filepath.Join(dotGit, "objects", id[:2], id[2:]), buf.String())
+}
+
+func gitRemoteURL(args Args) string {
+	for _, remote := range []string{args.Repo.Remote, args.Git.HTTPURL, args.Repo.Link} {
+		if remote != "" {
+			return strings.TrimSpace(remote)
+		}
+	}
+	return ""
+}
+
+func writeGitFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating git metadata: %s", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("error creating git metadata: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithGitInfo(t *testing.T) {
+	args := Args{BuildName: RtBuildName, BuildNumber: RtBuildNumber, AddGitInfo: true}
+	cmdList := jfCommands([][]string{
+		{"config", "add", tmpServerId},
+		{"mvn", "deploy"},
+		{"rt", "build-publish", RtBuildName, RtBuildNumber},
+	})
+
+	got := withGitInfo(args, cmdList)
+	want := []string{
+		"config add " + tmpServerId,
+		"mvn deploy",
+		"rt build-add-git t2 v1.0",
+		"rt build-publish t2 v1.0",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d commands, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if commandLine(got[i]) != want[i] {
+			t.Errorf("Command mismatch at index %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	if again := withGitInfo(args, got); len(again) != len(got) {
+		t.Errorf("Expected build-add-git to be inserted once, got %q", again)
+	}
+
+	args.AddGitInfo = false
+	if got := withGitInfo(args, cmdList); len(got) != len(cmdList) {
+		t.Errorf("Expected no build-add-git when disabled, got %q", got)
+	}
+}
+
+func TestPrepareGitInfoSynthesizesDotGit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	args := Args{AddGitInfo: true, workDir: t.TempDir()}
+	args.Commit.Rev = "0123456789abcdef0123456789abcdef01234567"
+	args.Commit.Branch = "feature/vcs"
+	args.Commit.Message = "Fix the build\n\nLonger description"
+	args.Commit.Author.Name = "Octocat"
+	args.Commit.Author.Email = "octocat@github.com"
+	args.Repo.Remote = "https://github.com/octocat/hello-world.git"
+
+	if err := prepareGitInfo(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.gitDir == "" {
+		t.Fatalf("Expected a synthesized git directory")
+	}
+
+	object, err := os.Open(filepath.Join(args.gitDir, ".git", "objects", args.Commit.Rev[:2], args.Commit.Rev[2:]))
+	if err != nil {
+		t.Fatalf("Expected the commit object to be written: %v", err)
+	}
+	defer object.Close()
+	zr, err := zlib.NewReader(object)
+	if err != nil {
+		t.Fatalf("Expected a zlib compressed commit object: %v", err)
+	}
+	commit, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the commit object: %v", err)
+	}
+	for _, want := range []string{"commit ", "\nauthor Octocat <octocat@github.com> ", "\n\nFix the build\n\nLonger description\n"} {
+		if !strings.Contains(string(commit), want) {
+			t.Errorf("Expected the commit object to contain %q, got %q", want, commit)
+		}
+	}
+
+	files := map[string]string{
+		"HEAD":                   "ref: refs/heads/feature/vcs\n",
+		"refs/heads/feature/vcs": args.Commit.Rev + "\n",
+	}
+	for name, want := range files {
+		content, err := os.ReadFile(filepath.Join(args.gitDir, ".git", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		if string(content) != want {
+			t.Errorf("%s: expected %q, got %q", name, want, content)
+		}
+	}
+	config, err := os.ReadFile(filepath.Join(args.gitDir, ".git", "config"))
+	if err != nil || !strings.Contains(string(config), "url = "+args.Repo.Remote) {
+		t.Errorf("Expected the remote url in the git config, got %q (%v)", config, err)
+	}
+
+	cmdArgs := GetBuildAddGitCommandArgs(args)
+	if cmdArgs[len(cmdArgs)-1] != args.gitDir {
+		t.Errorf("Expected build-add-git to read the synthesized directory, got %q", cmdArgs)
+	}
+}
+
+func TestPrepareGitInfoUsesCheckout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	args := Args{AddGitInfo: true, workDir: t.TempDir()}
+	args.Commit.Rev = "0123456789abcdef0123456789abcdef01234567"
+
+	if err := prepareGitInfo(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.gitDir != "" {
+		t.Errorf("Expected the checkout to be used, got %q", args.gitDir)
+	}
+}
+
+func TestExecUploadAddsGitInfoBeforePublish(t *testing.T) {
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/app.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		AddGitInfo:       true,
+	}
+	var commands []string
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		cmd := strings.Join(argv, " ")
+		commands = append(commands, cmd)
+		if strings.Contains(cmd, "build-add-git") {
+			return ExecResult{ExitCode: 1}, errors.New("exit status 1")
+		}
+		return ExecResult{}, nil
+	})
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Expected a build-add-git failure not to fail the run: %v", err)
+	}
+
+	wantCmds := []string{"jf config add", "jf rt u", "jf config add", "jf rt build-add-git t2 v1.0",
+		"jf rt build-publish t2 v1.0"}
+	if len(commands) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(commands), commands)
+	}
+	for i, want := range wantCmds {
+		if !strings.HasPrefix(commands[i], want) {
+			t.Errorf("Command mismatch at index %d: expected prefix %q, got %q", i, want, commands[i])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GoOptions are the settings of jf go-config, jf go and jf go-publish.
+type GoOptions struct {
+	Args            string `split_words:"true"`
+	Version         string `split_words:"true"`
+	RepoResolve     string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	ServerIdResolve string `split_words:"true"`
+	ServerIdDeploy  string `split_words:"true"`
+	Global          bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+}
+
+// NewGoOptions returns the go options of args, overridden by the PLUGIN_GO_
+// environment variables. The version defaults to the semver or the tag of
+// the pipeline.
+func NewGoOptions(args Args) (GoOptions, error) {
+	opts := GoOptions{
+		Args:            args.GoArgs,
+		Version:         valueOrDefault(args.Version, valueOrDefault(args.Semver.Version, args.Tag.Name)),
+		RepoResolve:     args.RepoResolve,
+		RepoDeploy:      args.RepoDeploy,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		ServerIdResolve: args.ServerIdResolve,
+		ServerIdDeploy:  args.ServerIdDeploy,
+		Global:          args.Global,
+		DetailedSummary: args.DetailedSummary,
+	}
+	err := loadOptions(GoOptionsPrefix, &opts)
+	opts.Version = goModuleVersion(opts.Version)
+	return opts, err
+}
+
+// ValidateBuild returns the problems of the go options for building the
+// module.
+func (o GoOptions) ValidateBuild() []error {
+	var problems []error
+	if o.RepoResolve == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_RESOLVE needs to be set"))
+	}
+	return problems
+}
+
+// ValidatePublish returns the problems of the go options for publishing
+// the module.
+func (o GoOptions) ValidatePublish() []error {
+	var problems []error
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	if o.Version == "" {
+		problems = append(problems, errors.New("PLUGIN_VERSION needs to be set when the pipeline has no tag"))
+	}
+	return problems
+}
+
+// ConfigFlags returns the flags of jf go-config.
+func (o GoOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addString("--repo-deploy", o.RepoDeploy)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-deploy", o.ServerIdDeploy)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	return flags
+}
+
+// goModuleVersion returns version with the v prefix go module versions
+// require, as the pipeline semver of a v1.2.3 tag is 1.2.3.
+func goModuleVersion(version string) string {
+	if version == "" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+func GetGoBuildCommandArgs(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewGoOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	goConfigCommandArgs := append([]string{GoConfig}, opts.ConfigFlags()...)
+
+	goArgs, err := splitArgs(opts.Args)
+	if err != nil {
+		return cmdList, fmt.Errorf("invalid PLUGIN_GO_ARGS: %w", err)
+	}
+	goBuildCommandArgs := append([]string{GoCmd, "build"}, goArgs...)
+	goBuildCommandArgs = append(goBuildCommandArgs, moduleBuildFlags(args)...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, goConfigCommandArgs)
+	cmdList = append(cmdList, goBuildCommandArgs)
+
+	return cmdList, nil
+}
+
+func GetGoPublishCommand(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewGoOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidatePublish()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	opts.ServerIdDeploy = valueOrDefault(opts.ServerIdDeploy, serverId)
+	if opts.RepoResolve != "" {
+		opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	}
+	goConfigCommandArgs := append([]string{GoConfig}, opts.ConfigFlags()...)
+
+	goPublishCommandArgs := append([]string{GoPublish, opts.Version}, moduleBuildFlags(args)...)
+	var publishFlags cmdFlags
+	publishFlags.addBool("--detailed-summary", opts.DetailedSummary)
+	goPublishCommandArgs = append(goPublishCommandArgs, publishFlags...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, goConfigCommandArgs)
+	cmdList = append(cmdList, goPublishCommandArgs)
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, buildDiscardCmd)
+	}
+
+	return cmdList, nil
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetGoBuildCommandArgs(t *testing.T) {
+	args := Args{
+		BuildTool:   "go",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoResolve: "go-virtual",
+		GoArgs:      "-o app ./cmd/app",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	cmdList, err := GetGoBuildCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add tmpServerId --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+		"go-config --repo-resolve=go-virtual --server-id-resolve=tmpServerId",
+		"go build -o app ./cmd/app --build-name=t2 --build-number=v1.0",
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := strings.Join(cmd, " "); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestGetGoPublishCommand(t *testing.T) {
+	args := Args{
+		BuildTool:       "go",
+		Command:         "publish",
+		Username:        "ab",
+		Password:        "cd",
+		URL:             RtUrlTestStr,
+		RepoDeploy:      "go-local",
+		DeployerId:      RtDeployerId,
+		BuildName:       RtBuildName,
+		BuildNumber:     RtBuildNumber,
+		DetailedSummary: true,
+	}
+	args.Semver.Version = "1.2.3"
+
+	cmdList, err := GetGoPublishCommand(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
+			" --user=ab --password-stdin --interactive=false",
+		"go-config --repo-deploy=go-local --server-id-deploy=" + RtDeployerId,
+		"go-publish v1.2.3 --build-name=t2 --build-number=v1.0 --detailed-summary=true",
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := strings.Join(cmd, " "); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestGoOptionsVersion(t *testing.T) {
+	_, err := GetGoPublishCommand(Args{RepoDeploy: "go-local"})
+	want := "PLUGIN_VERSION needs to be set when the pipeline has no tag"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+
+	tests := []struct {
+		version string
+		tag     string
+		env     string
+		want    string
+	}{
+		{"v2.0.0", "", "", "v2.0.0"},
+		{"", "v1.4.0", "", "v1.4.0"},
+		{"", "", "", ""},
+		{"2.0.0", "", "3.0.0-rc.1", "v3.0.0-rc.1"},
+	}
+	for _, tc := range tests {
+		if tc.env != "" {
+			t.Setenv("PLUGIN_GO_VERSION", tc.env)
+		}
+		args := Args{Version: tc.version}
+		args.Tag.Name = tc.tag
+		opts, err := NewGoOptions(args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if opts.Version != tc.want {
+			t.Errorf("Expected version %q, got %q", tc.want, opts.Version)
+		}
+	}
+}
@@ -1,80 +1,52 @@
 package plugin
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 
 	"github.com/sirupsen/logrus"
 )
 
-var GradleConfigJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--deploy-ivy-desc=", "PLUGIN_DEPLOY_IVY_DESC", false, false},
-	{"--deploy-maven-desc=", "PLUGIN_DEPLOY_MAVEN_DESC", false, false},
-	{"--global=", "PLUGIN_GLOBAL", false, false},
-	{"--ivy-artifacts-pattern=", "PLUGIN_IVY_ARTIFACTS_PATTERN", false, false},
-	{"--ivy-desc-pattern=", "PLUGIN_IVY_DESC_PATTERN", false, false},
-	{"--repo-deploy=", "PLUGIN_REPO_DEPLOY", false, false},
-	{"--repo-resolve=", "PLUGIN_REPO_RESOLVE", false, false},
-	{"--server-id-deploy=", "PLUGIN_SERVER_ID_DEPLOY", false, false},
-	{"--server-id-resolve=", "PLUGIN_SERVER_ID_RESOLVE", false, false},
-	{"--use-wrapper=", "PLUGIN_USE_WRAPPER", false, false},
-	{"--uses-plugin=", "PLUGIN_USES_PLUGIN", false, false},
-}
-
-var GradleRunJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--build-name=", "PLUGIN_BUILD_NAME", false, false},
-	{"--build-number=", "PLUGIN_BUILD_NUMBER", false, false},
-	{"--detailed-summary=", "PLUGIN_DETAILED_SUMMARY", false, false},
-	{"--format=", "PLUGIN_FORMAT", false, false},
-	{"--project=", "PLUGIN_PROJECT", false, false},
-	{"--scan=", "PLUGIN_SCAN", false, false},
-	{"--threads=", "PLUGIN_THREADS", false, false},
-}
-
 func GetGradleCommandArgs(args Args) ([][]string, error) {
 
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(args.ResolverId,
+	opts, err := NewGradleOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
 
-	gradleConfigCommandArgs := []string{GradleConfig}
 	// Add necessary parameters for Windows to prevent all interactive prompts
 	if runtime.GOOS == "windows" {
-		// These parameters prevent all interactive prompts
-		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--global=true")
-		// Add server ID for deployment/resolution
-		if args.ResolverId != "" {
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+args.ResolverId)
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
-		}
-		// Add repos to prevent prompts
-		if args.RepoResolve == "" {
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--repo-resolve=libs-release")
-		}
-		if args.RepoDeploy == "" {
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--repo-deploy=libs-release-local")
-		}
-		// Use maven-style plugin to enable dependency resolution
-		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--uses-plugin=true")
+		opts.setNonInteractiveDefaults()
 	}
+	gradleConfigCommandArgs := append([]string{GradleConfig}, opts.ConfigFlags()...)
 
-	err = PopulateArgs(&gradleConfigCommandArgs, &args, GradleConfigJsonTagToExeFlagMapStringItemList)
+	tasks, err := splitArgs(opts.Tasks)
 	if err != nil {
-		return cmdList, err
+		return cmdList, fmt.Errorf("invalid PLUGIN_TASKS: %w", err)
 	}
-
-	gradleTaskCommandArgs := []string{GradleCmd, args.GradleTasks}
-	err = PopulateArgs(&gradleTaskCommandArgs, &args, GradleRunJsonTagToExeFlagMapStringItemList)
-	if err != nil {
-		return cmdList, err
+	gradleTaskCommandArgs := append([]string{GradleCmd}, tasks...)
+	gradleTaskCommandArgs = append(gradleTaskCommandArgs, buildFlags(args)...)
+	gradleTaskCommandArgs = append(gradleTaskCommandArgs, opts.RunFlags()...)
+	if args.Project != "" {
+		gradleTaskCommandArgs = append(gradleTaskCommandArgs, "--project="+args.Project)
 	}
 
-	if len(args.BuildFile) > 0 {
-		gradleTaskCommandArgs = append(gradleTaskCommandArgs, "-b "+args.BuildFile)
+	if len(opts.BuildFile) > 0 {
+		gradleTaskCommandArgs = append(gradleTaskCommandArgs, "-b", opts.BuildFile)
 	}
 
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
@@ -84,48 +56,37 @@ func GetGradleCommandArgs(args Args) ([][]string, error) {
 	return cmdList, nil
 }
 
-var GradleConfigCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--exclude-patterns=", "PLUGIN_EXCLUDE_PATTERNS", false, false},
-	{"--global=", "PLUGIN_GLOBAL", false, false},
-	{"--include-patterns=", "PLUGIN_INCLUDE_PATTERNS", false, false},
-	{"--repo-deploy-releases=", "PLUGIN_DEPLOY_RELEASE_REPO", false, false},
-	{"--repo-deploy-snapshots=", "PLUGIN_DEPLOY_SNAPSHOT_REPO", false, false},
-	{"--repo-resolve-releases=", "PLUGIN_RESOLVE_RELEASE_REPO", false, false},
-	{"--repo-resolve-snapshots=", "PLUGIN_RESOLVE_SNAPSHOT_REPO", false, false},
-	{"--repo-deploy=", "PLUGIN_REPO_DEPLOY", false, false},
-	{"--repo-resolve=", "PLUGIN_REPO_RESOLVE", false, false},
-	{"--server-id-deploy=", "PLUGIN_SERVER_ID_DEPLOY", false, false},
-	{"--server-id-resolve=", "PLUGIN_RESOLVER_ID", false, false},
-	{"--use-wrapper=", "PLUGIN_USE_WRAPPER", false, false},
-}
-
 func GetGradlePublishCommand(args Args) ([][]string, error) {
 
 	var cmdList [][]string
 	var jfrogConfigAddConfigCommandArgs []string
 
-	tmpServerId := args.DeployerId
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
-		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	opts, err := NewGradleOptions(args)
 	if err != nil {
-		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+	if err := errors.Join(opts.Validate()...); err != nil {
 		return cmdList, err
 	}
 
-	gradleConfigCommandArgs := []string{GradleConfig}
-	err = PopulateArgs(&gradleConfigCommandArgs, &args, GradleConfigCmdJsonTagToExeFlagMapStringItemList)
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err = GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
-		logrus.Println("PopulateArgs error: ", err)
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
 		return cmdList, err
 	}
-	gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+tmpServerId)
-	gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+tmpServerId)
+
+	// deploy and resolve through the deployer server unless set explicitly
+	opts.ServerIdDeploy = valueOrDefault(opts.ServerIdDeploy, serverId)
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, valueOrDefault(opts.ResolverId, serverId))
+	gradleConfigCommandArgs := append([]string{GradleConfig}, opts.ConfigFlags()...)
 
 	rtPublishCommandArgs := []string{"gradle", Publish}
 	switch {
 	case args.Username != "":
+		// the password project property is set through the environment
 		rtPublishCommandArgs = append(rtPublishCommandArgs, "-Pusername="+args.Username)
-		rtPublishCommandArgs = append(rtPublishCommandArgs, "-Ppassword="+args.Password)
 	case args.AccessToken != "":
 		errMsg := "AccessToken is not supported for Gradle" +
 			" try username: <username> , password: <access_token> instead"
@@ -136,7 +97,7 @@ func GetGradlePublishCommand(args Args) ([][]string, error) {
 	rtPublishCommandArgs = append(rtPublishCommandArgs, "--build-number="+args.BuildNumber)
 
 	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
-		"--server-id=" + tmpServerId}
+		"--server-id=" + serverId}
 	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
 	if err != nil {
 		logrus.Println("PopulateArgs error: ", err)
@@ -149,12 +110,11 @@ func GetGradlePublishCommand(args Args) ([][]string, error) {
 	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
 
 	if IsBuildDiscardArgs(args) {
-		buildDiscardBuildArgsList, err := GetBuildDiscardCommandArgs(args)
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
 		if err != nil {
-			logrus.Println("GetBuildDiscardCommandArgs error: ", err)
 			return cmdList, err
 		}
-		cmdList = append(cmdList, buildDiscardBuildArgsList...)
+		cmdList = append(cmdList, buildDiscardCmd)
 	}
 
 	return cmdList, nil
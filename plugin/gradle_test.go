@@ -1,6 +1,8 @@
 package plugin
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -25,8 +27,9 @@ func TestGetGradleBuildCommandArgs(t *testing.T) {
 			},
 			output: []string{
 				"config add tmpServerId --url=https://artifactory.test.io/artifactory/ " +
-					"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
-				"gradle-config --repo-deploy=" + RtTestRelRepo + " --repo-resolve=" + RtResolveRelRepo,
+					"--user=user --password-stdin --interactive=false",
+				"gradle-config --repo-deploy=" + RtTestRelRepo + " --repo-resolve=" + RtResolveRelRepo +
+					" --server-id-resolve=tmpServerId",
 				"gradle clean build --build-name=" + RtBuildName + " --build-number=" + RtBuildNumber,
 			},
 			err: nil,
@@ -74,10 +77,10 @@ func TestGetGradlePublishCommandArgs(t *testing.T) {
 			},
 			output: []string{
 				"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
-					" --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+					" --user=user --password-stdin --interactive=false",
 				"gradle-config --repo-deploy=" + RtTestRelRepo + " --repo-resolve=" +
 					RtResolveRelRepo + " --server-id-deploy=" + RtDeployerId + " --server-id-resolve=" + RtDeployerId,
-				"gradle publish -Pusername=user -Ppassword=pass --build-name=" +
+				"gradle publish -Pusername=user --build-name=" +
 					RtBuildName + " --build-number=" + RtBuildNumber,
 				"rt build-publish " + RtBuildName + " " + RtBuildNumber + " --server-id=" + RtDeployerId,
 			},
@@ -104,3 +107,42 @@ func TestGetGradlePublishCommandArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestGradleTasksAreSplitLikeAShell(t *testing.T) {
+	args := Args{
+		BuildTool:   "gradle",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoResolve: RtResolveRelRepo,
+		GradleTasks: `build "-Pgreeting=hello world"`,
+	}
+	cmdList, err := GetGradleCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{GradleCmd, "build", "-Pgreeting=hello world"}
+	if got := cmdList[2][:3]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	args.GradleTasks = `build "-Pgreeting=hello`
+	if _, err := GetGradleCommandArgs(args); err == nil || !strings.Contains(err.Error(), "PLUGIN_TASKS") {
+		t.Errorf("Expected an invalid PLUGIN_TASKS error, got %v", err)
+	}
+}
+
+func TestGradlePublishPasswordPassedThroughEnv(t *testing.T) {
+	args := Args{Username: "user", Password: "pass"}
+	var env []string
+	executor := executorFunc(func(ctx context.Context, argv []string, cmdEnv []string) (ExecResult, error) {
+		env = cmdEnv
+		return ExecResult{}, nil
+	})
+	cmdArgs := []string{getJfrogBin(), GradleCmd, Publish, "-Pusername=user"}
+	if _, err := runCommand(context.Background(), executor, args, cmdArgs, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := envValue(env, gradlePasswordEnv); got != "pass" {
+		t.Errorf("Expected %s to hold the password, got %q", gradlePasswordEnv, got)
+	}
+}
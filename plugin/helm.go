@@ -0,0 +1,123 @@
+package plugin
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// helmChartsDir is the directory of the run the chart is packaged to.
+const helmChartsDir = "charts"
+
+// HelmOptions are the settings of the helm-publish command.
+type HelmOptions struct {
+	ChartPath       string `split_words:"true"`
+	Version         string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	Reindex         bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+}
+
+// NewHelmOptions returns the helm options of args, overridden by the
+// PLUGIN_HELM_ environment variables. The chart version defaults to the
+// semver of the pipeline, and to the version of Chart.yaml without one.
+func NewHelmOptions(args Args) (HelmOptions, error) {
+	opts := HelmOptions{
+		ChartPath:       args.ChartPath,
+		Version:         valueOrDefault(args.Version, args.Semver.Version),
+		RepoDeploy:      args.RepoDeploy,
+		DeployerId:      args.DeployerId,
+		Reindex:         args.Reindex,
+		DetailedSummary: args.DetailedSummary,
+	}
+	err := loadOptions(HelmOptionsPrefix, &opts)
+	opts.ChartPath = valueOrDefault(opts.ChartPath, ".")
+	return opts, err
+}
+
+// Validate returns the problems of the helm options.
+func (o HelmOptions) Validate() []error {
+	var problems []error
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	return problems
+}
+
+// repo returns the name of the helm repository the chart is deployed to.
+func (o HelmOptions) repo() string {
+	return strings.Trim(o.RepoDeploy, "/")
+}
+
+func GetHelmPublishCommandArgs(args Args) ([]Command, error) {
+
+	var cmdList []Command
+
+	opts, err := NewHelmOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	// package to a directory of its own, the name of the archive depends on
+	// the name and version of the chart
+	destination := filepath.ToSlash(tempFilePath(args, helmChartsDir))
+	helmPackageCommand := Command{Program: HelmCmd,
+		Args: []string{"package", opts.ChartPath, "--destination", destination}}
+	if opts.Version != "" {
+		helmPackageCommand.Args = append(helmPackageCommand.Args, "--version", opts.Version)
+	}
+
+	rtUploadCommandArgs := []string{"rt", "u", destination + "/*.tgz", opts.repo() + "/",
+		"--server-id=" + serverId, "--flat=true"}
+	rtUploadCommandArgs = append(rtUploadCommandArgs, moduleBuildFlags(args)...)
+	var uploadFlags cmdFlags
+	uploadFlags.addBool("--detailed-summary", opts.DetailedSummary)
+	rtUploadCommandArgs = append(rtUploadCommandArgs, uploadFlags...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, Command{Args: jfrogConfigAddConfigCommandArgs})
+	cmdList = append(cmdList, helmPackageCommand)
+	cmdList = append(cmdList, Command{Args: rtUploadCommandArgs})
+
+	if opts.Reindex {
+		// Artifactory recalculates the index.yaml of the repository
+		// asynchronously after the upload, reindexing makes the chart
+		// available right away
+		rtReindexCommandArgs := []string{"rt", "curl", "-XPOST", "/api/helm/" + opts.repo() + "/reindex",
+			"--server-id=" + serverId}
+		cmdList = append(cmdList, Command{Args: rtReindexCommandArgs})
+	}
+
+	cmdList = append(cmdList, Command{Args: rtPublishBuildInfoCommandArgs})
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, Command{Args: buildDiscardCmd})
+	}
+
+	return cmdList, nil
+}
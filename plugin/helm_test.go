@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetHelmPublishCommandArgs(t *testing.T) {
+	args := Args{
+		Command:     "helm-publish",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		ChartPath:   "deploy/app",
+		RepoDeploy:  "helm-local/",
+		DeployerId:  RtDeployerId,
+		Reindex:     true,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	args.Semver.Version = "1.2.3"
+
+	cmdList, err := GetRtCommandsList(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+		"helm package deploy/app --destination charts --version 1.2.3",
+		"rt u charts/*.tgz helm-local/ --server-id=" + RtDeployerId + " --flat=true --build-name=t2 --build-number=v1.0",
+		"rt curl -XPOST /api/helm/helm-local/reindex --server-id=" + RtDeployerId,
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := commandLine(cmd); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestHandleRtCommandsHelmPublishRunsHelm(t *testing.T) {
+	args := Args{
+		Command:     "helm-publish",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoDeploy:  "helm-local",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		workDir:     t.TempDir(),
+	}
+	executor := &recordingExecutor{}
+	if err := HandleRtCommands(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(executor.argvs) < 3 {
+		t.Fatalf("Expected the chart to be packaged and uploaded, got %v", executor.commands)
+	}
+	if got := executor.argvs[1][0]; got != HelmCmd {
+		t.Errorf("Expected the chart to be packaged by helm, got %q", got)
+	}
+	if got := executor.argvs[1][2]; got != "." {
+		t.Errorf("Expected the chart of the working directory, got %q", got)
+	}
+	if got := executor.argvs[2][0]; got != getJfrogBin() {
+		t.Errorf("Expected the chart to be uploaded by jf, got %q", got)
+	}
+}
+
+func TestHelmOptionsValidate(t *testing.T) {
+	t.Setenv("PLUGIN_HELM_VERSION", "2.0.0")
+
+	args := Args{Version: "1.0.0"}
+	args.Semver.Version = "1.2.3"
+	opts, err := NewHelmOptions(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Version != "2.0.0" {
+		t.Errorf("Expected the version from the environment, got %q", opts.Version)
+	}
+	if problems := opts.Validate(); len(problems) != 1 || problems[0].Error() != "PLUGIN_REPO_DEPLOY needs to be set" {
+		t.Errorf("Expected a missing deploy repo, got %v", problems)
+	}
+}
@@ -1,83 +1,49 @@
 package plugin
 
 import (
+	"errors"
+	"fmt"
 	"runtime"
 
 	"github.com/sirupsen/logrus"
 )
 
-var MavenRunCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--build-name=", "PLUGIN_BUILD_NAME", false, false},
-	{"--build-number=", "PLUGIN_BUILD_NUMBER", false, false},
-	{"--detailed-summary=", "PLUGIN_DETAILED_SUMMARY", false, false},
-	{"--format=", "PLUGIN_FORMAT", false, false},
-	{"--insecure-tls=", "PLUGIN_INSECURE", false, false},
-	{"--project=", "PLUGIN_PROJECT", false, false},
-	{"--scan=", "PLUGIN_SCAN", false, false},
-	{"--threads=", "PLUGIN_THREADS", false, false},
-}
-
-var MavenConfigCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--exclude-patterns=", "PLUGIN_EXCLUDE_PATTERNS", false, false},
-	{"--global=", "PLUGIN_GLOBAL", false, false},
-	{"--include-patterns=", "PLUGIN_INCLUDE_PATTERNS", false, false},
-	{"--repo-deploy-releases=", "PLUGIN_DEPLOY_RELEASE_REPO", false, false},
-	{"--repo-deploy-snapshots=", "PLUGIN_DEPLOY_SNAPSHOT_REPO", false, false},
-	{"--repo-resolve-releases=", "PLUGIN_RESOLVE_RELEASE_REPO", false, false},
-	{"--repo-resolve-snapshots=", "PLUGIN_RESOLVE_SNAPSHOT_REPO", false, false},
-	{"--server-id-deploy=", "PLUGIN_SERVER_ID_DEPLOY", false, false},
-	{"--server-id-resolve=", "PLUGIN_RESOLVER_ID", false, false},
-	{"--use-wrapper=", "PLUGIN_USE_WRAPPER", false, false},
-}
-
 func GetMavenBuildCommandArgs(args Args) ([][]string, error) {
 
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(args.ResolverId,
+	opts, err := NewMavenOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverIDOrDefault(args, opts.ResolverId),
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
 
-	mvnConfigCommandArgs := []string{MvnConfig}
 	// Add necessary parameters for Windows to prevent all interactive prompts
 	if runtime.GOOS == "windows" {
-		// These parameters prevent all interactive prompts
-		mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--global=true")
-		// Add server ID for deployment/resolution
-		if args.ResolverId != "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--server-id-resolve="+args.ResolverId)
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
-		}
-		// Add repos to prevent prompts
-		// Must set both release and snapshot repos to prevent errors
-		if args.ResolveReleaseRepo == "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--repo-resolve-releases=libs-release")
-		}
-		if args.ResolveSnapshotRepo == "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--repo-resolve-snapshots=libs-snapshot")
-		}
-		if args.DeployReleaseRepo == "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--repo-deploy-releases=libs-release-local")
-		}
-		if args.DeploySnapshotRepo == "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--repo-deploy-snapshots=libs-snapshot-local")
-		}
+		opts.setNonInteractiveDefaults()
 	}
+	mvnConfigCommandArgs := append([]string{MvnConfig}, opts.ConfigFlags()...)
 
-	err = PopulateArgs(&mvnConfigCommandArgs, &args, MavenConfigCmdJsonTagToExeFlagMapStringItemList)
+	goals, err := splitArgs(opts.Goals)
 	if err != nil {
-		return cmdList, err
+		return cmdList, fmt.Errorf("invalid PLUGIN_GOALS: %w", err)
 	}
-
-	mvnRunCommandArgs := []string{MvnCmd, args.MvnGoals}
-	err = PopulateArgs(&mvnRunCommandArgs, &args, MavenRunCmdJsonTagToExeFlagMapStringItemList)
-	if err != nil {
-		return cmdList, err
+	mvnRunCommandArgs := append([]string{MvnCmd}, goals...)
+	mvnRunCommandArgs = append(mvnRunCommandArgs, buildFlags(args)...)
+	mvnRunCommandArgs = append(mvnRunCommandArgs, opts.RunFlags()...)
+	if args.Project != "" {
+		mvnRunCommandArgs = append(mvnRunCommandArgs, "--project="+args.Project)
 	}
-	if len(args.MvnPomFile) > 0 {
-		mvnRunCommandArgs = append(mvnRunCommandArgs, "-f "+args.MvnPomFile)
+	if len(opts.PomFile) > 0 {
+		mvnRunCommandArgs = append(mvnRunCommandArgs, "-f", opts.PomFile)
 	}
 
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
@@ -95,21 +61,24 @@ func GetMavenPublishCommand(args Args) ([][]string, error) {
 	var cmdList [][]string
 	var jfrogConfigAddConfigCommandArgs []string
 
-	tmpServerId := args.DeployerId
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
-		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	opts, err := NewMavenOptions(args)
 	if err != nil {
-		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+	if err := errors.Join(opts.Validate()...); err != nil {
 		return cmdList, err
 	}
 
-	mvnConfigCommandArgs := []string{MvnConfig}
-	err = PopulateArgs(&mvnConfigCommandArgs, &args, MavenConfigCmdJsonTagToExeFlagMapStringItemList)
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err = GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
-		logrus.Println("mvnConfigCommandArgs PopulateArgs error: ", err)
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
 		return cmdList, err
 	}
 
+	mvnConfigCommandArgs := append([]string{MvnConfig}, opts.ConfigFlags()...)
+
 	rtPublishCommandArgs := []string{MvnCmd, Deploy,
 		"--build-name=" + args.BuildName, "--build-number=" + args.BuildNumber}
 	err = PopulateArgs(&rtPublishCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
@@ -119,7 +88,7 @@ func GetMavenPublishCommand(args Args) ([][]string, error) {
 	}
 
 	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
-		"--server-id=" + tmpServerId}
+		"--server-id=" + serverId}
 	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
 	if err != nil {
 		logrus.Println("PopulateArgs error: ", err)
@@ -132,12 +101,11 @@ func GetMavenPublishCommand(args Args) ([][]string, error) {
 	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
 
 	if IsBuildDiscardArgs(args) {
-		buildDiscardBuildArgsList, err := GetBuildDiscardCommandArgs(args)
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
 		if err != nil {
-			logrus.Println("GetBuildDiscardCommandArgs error: ", err)
 			return cmdList, err
 		}
-		cmdList = append(cmdList, buildDiscardBuildArgsList...)
+		cmdList = append(cmdList, buildDiscardCmd)
 	}
 	return cmdList, nil
 }
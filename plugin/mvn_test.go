@@ -27,7 +27,7 @@ func TestGetMavenBuildCommandUserPassword(t *testing.T) {
 
 	wantCmds := []string{
 		"config add resolve_gen_maven_01 --url=https://artifactory.test.io/artifactory/ " +
-			"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+			"--user=ab --password-stdin --interactive=false",
 		"mvn-config --repo-resolve-releases=mvn_repo_resolve_releases_01 " +
 			"--repo-resolve-snapshots=mvn_repo_resolve_snapshots_01 --server-id-resolve=resolve_gen_maven_01",
 		"mvn clean install --build-name=t2 --build-number=v1.0 -f pom.xml",
@@ -64,7 +64,7 @@ func TestGetMavenBuildCommandAccessToken(t *testing.T) {
 
 	wantCmds := []string{
 		"config add resolve_gen_maven_01 --url=https://artifactory.test.io/artifactory/ " +
-			"--access-token $PLUGIN_ACCESS_TOKEN --interactive=false",
+			"--access-token-stdin --interactive=false",
 		"mvn-config --repo-resolve-releases=mvn_repo_resolve_releases_01 " +
 			"--repo-resolve-snapshots=mvn_repo_resolve_snapshots_01 --server-id-resolve=resolve_gen_maven_01",
 		"mvn clean install --build-name=t2 --build-number=v1.0 -f pom.xml",
@@ -98,7 +98,7 @@ func TestGetMavenPublishCommandUserNamePassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
 		"mvn-config --repo-deploy-releases=mvn_repo_deploy_releases_01 --repo-deploy-snapshots=mvn_repo_deploy_snapshots_01",
 		"mvn deploy --build-name=t2 --build-number=v1.0",
 		"rt build-publish t2 v1.0 --server-id=deploy_gen_maven_01",
@@ -132,7 +132,7 @@ func TestGetMavenPublishCommandAccessToken(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --access-token $PLUGIN_ACCESS_TOKEN --interactive=false",
+		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --access-token-stdin --interactive=false",
 		"mvn-config --repo-deploy-releases=mvn_repo_deploy_releases_01 --repo-deploy-snapshots=mvn_repo_deploy_snapshots_01",
 		"mvn deploy --build-name=t2 --build-number=v1.0",
 		"rt build-publish t2 v1.0 --server-id=deploy_gen_maven_01",
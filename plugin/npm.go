@@ -0,0 +1,227 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// npm install commands, ci installs exactly the versions of the lockfile.
+const (
+	npmCi      = "ci"
+	npmInstall = "install"
+)
+
+// NpmOptions are the settings of jf npm-config and jf npm.
+type NpmOptions struct {
+	InstallCommand  string `split_words:"true"`
+	Args            string `split_words:"true"`
+	RepoResolve     string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	ServerIdResolve string `split_words:"true"`
+	ServerIdDeploy  string `split_words:"true"`
+	Global          bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+	Format          string `split_words:"true"`
+	Scan            bool   `split_words:"true"`
+	Threads         int    `split_words:"true"`
+}
+
+// NewNpmOptions returns the npm options of args, overridden by the
+// PLUGIN_NPM_ environment variables.
+func NewNpmOptions(args Args) (NpmOptions, error) {
+	opts := NpmOptions{
+		InstallCommand:  args.InstallCommand,
+		Args:            args.NpmArgs,
+		RepoResolve:     args.RepoResolve,
+		RepoDeploy:      args.RepoDeploy,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		ServerIdResolve: args.ServerIdResolve,
+		ServerIdDeploy:  args.ServerIdDeploy,
+		Global:          args.Global,
+		DetailedSummary: args.DetailedSummary,
+		Format:          args.Format,
+		Scan:            args.Scan,
+		Threads:         args.Threads,
+	}
+	return opts, loadOptions(NpmOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the npm options.
+func (o NpmOptions) Validate() []error {
+	problems := append(notNegative("PLUGIN_THREADS", o.Threads), checkFormat(o.Format)...)
+	switch o.InstallCommand {
+	case "", npmCi, npmInstall:
+	default:
+		problems = append(problems, fmt.Errorf("PLUGIN_INSTALL_COMMAND %q is not one of %s, %s",
+			o.InstallCommand, npmCi, npmInstall))
+	}
+	return problems
+}
+
+// ValidateBuild returns the problems of the npm options for installing the
+// dependencies.
+func (o NpmOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if o.RepoResolve == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_RESOLVE needs to be set"))
+	}
+	return problems
+}
+
+// ValidatePublish returns the problems of the npm options for publishing
+// the package.
+func (o NpmOptions) ValidatePublish() []error {
+	problems := o.Validate()
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	return problems
+}
+
+// ConfigFlags returns the flags of jf npm-config.
+func (o NpmOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addString("--repo-deploy", o.RepoDeploy)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-deploy", o.ServerIdDeploy)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	return flags
+}
+
+// PublishFlags returns the flags of jf npm publish besides the build name
+// and number.
+func (o NpmOptions) PublishFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--detailed-summary", o.DetailedSummary)
+	flags.addString("--format", o.Format)
+	flags.addBool("--scan", o.Scan)
+	return flags
+}
+
+// installCommand returns the npm command installing the dependencies, ci
+// when the project has a lockfile and install otherwise.
+func (o NpmOptions) installCommand() string {
+	if o.InstallCommand != "" {
+		return o.InstallCommand
+	}
+	for _, lockfile := range []string{"package-lock.json", "npm-shrinkwrap.json"} {
+		if _, err := os.Stat(lockfile); err == nil {
+			return npmCi
+		}
+	}
+	return npmInstall
+}
+
+func GetNpmBuildCommandArgs(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewNpmOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	npmConfigCommandArgs := append([]string{NpmConfig}, opts.ConfigFlags()...)
+
+	npmInstallCommandArgs := []string{NpmCmd, opts.installCommand()}
+	npmArgs, err := splitArgs(opts.Args)
+	if err != nil {
+		return cmdList, fmt.Errorf("invalid PLUGIN_NPM_ARGS: %w", err)
+	}
+	npmInstallCommandArgs = append(npmInstallCommandArgs, npmArgs...)
+	npmInstallCommandArgs = append(npmInstallCommandArgs, moduleBuildFlags(args)...)
+	var runFlags cmdFlags
+	runFlags.addInt("--threads", opts.Threads)
+	npmInstallCommandArgs = append(npmInstallCommandArgs, runFlags...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, npmConfigCommandArgs)
+	cmdList = append(cmdList, npmInstallCommandArgs)
+
+	return cmdList, nil
+}
+
+func GetNpmPublishCommand(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewNpmOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidatePublish()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	opts.ServerIdDeploy = valueOrDefault(opts.ServerIdDeploy, serverId)
+	if opts.RepoResolve != "" {
+		opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	}
+	npmConfigCommandArgs := append([]string{NpmConfig}, opts.ConfigFlags()...)
+
+	npmArgs, err := splitArgs(opts.Args)
+	if err != nil {
+		return cmdList, fmt.Errorf("invalid PLUGIN_NPM_ARGS: %w", err)
+	}
+	npmPublishCommandArgs := append([]string{NpmCmd, Publish}, npmArgs...)
+	npmPublishCommandArgs = append(npmPublishCommandArgs, moduleBuildFlags(args)...)
+	npmPublishCommandArgs = append(npmPublishCommandArgs, opts.PublishFlags()...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, npmConfigCommandArgs)
+	cmdList = append(cmdList, npmPublishCommandArgs)
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, buildDiscardCmd)
+	}
+
+	return cmdList, nil
+}
+
+// moduleBuildFlags returns the flags recording a package manager command in
+// a module of the build info of the step.
+func moduleBuildFlags(args Args) []string {
+	flags := cmdFlags(buildFlags(args))
+	flags.addString("--module", args.Module)
+	flags.addString("--project", args.Project)
+	return flags
+}
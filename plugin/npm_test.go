@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetNpmBuildCommandArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile bool
+		args     Args
+		output   []string
+	}{
+		{
+			name:     "ci with lockfile",
+			lockfile: true,
+			args: Args{
+				BuildTool:   "npm",
+				AccessToken: RtAccessToken,
+				URL:         RtUrlTestStr,
+				RepoResolve: "npm-virtual",
+				ResolverId:  RtRslvId,
+				BuildName:   RtBuildName,
+				BuildNumber: RtBuildNumber,
+			},
+			output: []string{
+				"config add " + RtRslvId + " --url=" + RtUrlTestStr +
+					" --access-token-stdin --interactive=false",
+				"npm-config --repo-resolve=npm-virtual --server-id-resolve=" + RtRslvId,
+				"npm ci --build-name=t2 --build-number=v1.0",
+			},
+		},
+		{
+			name: "install without lockfile",
+			args: Args{
+				BuildTool:   "npm",
+				Username:    "ab",
+				Password:    "cd",
+				URL:         RtUrlTestStr,
+				RepoResolve: "npm-virtual",
+				NpmArgs:     "--omit=dev",
+				Module:      "frontend",
+				Threads:     4,
+				BuildName:   RtBuildName,
+				BuildNumber: RtBuildNumber,
+			},
+			output: []string{
+				"config add tmpServerId --url=" + RtUrlTestStr +
+					" --user=ab --password-stdin --interactive=false",
+				"npm-config --repo-resolve=npm-virtual --server-id-resolve=tmpServerId",
+				"npm install --omit=dev --build-name=t2 --build-number=v1.0 --module=frontend --threads=4",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if tc.lockfile {
+				if err := os.WriteFile("package-lock.json", []byte("{}"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cmdList, err := GetNpmBuildCommandArgs(tc.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(cmdList) != len(tc.output) {
+				t.Fatalf("Expected %d commands, got %d: %v", len(tc.output), len(cmdList), cmdList)
+			}
+			for i, cmd := range cmdList {
+				if got := strings.Join(cmd, " "); got != tc.output[i] {
+					t.Errorf("Mismatch at index %d. Expected: %s, Got: %s", i, tc.output[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNpmPublishCommand(t *testing.T) {
+	args := Args{
+		BuildTool:   "npm",
+		Command:     "publish",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		RepoDeploy:  "npm-local",
+		DeployerId:  RtDeployerId,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Project:     "web",
+		MaxBuilds:   "5",
+	}
+	cmdList, err := GetNpmPublishCommand(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
+			" --user=ab --password-stdin --interactive=false",
+		"npm-config --repo-deploy=npm-local --server-id-deploy=" + RtDeployerId,
+		"npm publish --build-name=t2 --build-number=v1.0 --project=web",
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId + " --project=web",
+		"rt build-discard --max-builds=5 --server-id=" + RtDeployerId + " t2",
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := strings.Join(cmd, " "); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestNpmOptionsValidate(t *testing.T) {
+	tests := []struct {
+		command string
+		args    Args
+		want    string
+	}{
+		{"build", Args{}, "PLUGIN_REPO_RESOLVE needs to be set"},
+		{"publish", Args{}, "PLUGIN_REPO_DEPLOY needs to be set"},
+		{"build", Args{RepoResolve: "npm-virtual", InstallCommand: "update"},
+			`PLUGIN_INSTALL_COMMAND "update" is not one of ci, install`},
+	}
+	for _, tc := range tests {
+		rtCmd, err := LookupRtCommand(NpmCmd, tc.command)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		problems := rtCmd.Validate(tc.args)
+		if len(problems) != 1 || problems[0].Error() != tc.want {
+			t.Errorf("npm %s: expected %q, got %v", tc.command, tc.want, problems)
+		}
+	}
+}
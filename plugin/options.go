@@ -0,0 +1,570 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Prefixes of the environment variables setting the options of a single
+// command, e.g. PLUGIN_PROMOTE_TARGET sets the target of a promotion only.
+// These override the shared settings, such as PLUGIN_TARGET, which every
+// command of the step reads.
+const (
+	UploadOptionsPrefix  = "PLUGIN_UPLOAD"
+	MavenOptionsPrefix   = "PLUGIN_MAVEN"
+	GradleOptionsPrefix  = "PLUGIN_GRADLE"
+	PromoteOptionsPrefix = "PLUGIN_PROMOTE"
+	DiscardOptionsPrefix = "PLUGIN_DISCARD"
+	NpmOptionsPrefix     = "PLUGIN_NPM"
+	YarnOptionsPrefix    = "PLUGIN_YARN"
+	PnpmOptionsPrefix    = "PLUGIN_PNPM"
+	GoOptionsPrefix      = "PLUGIN_GO"
+	PythonOptionsPrefix  = "PLUGIN_PYTHON"
+	DotnetOptionsPrefix  = "PLUGIN_DOTNET"
+	DockerOptionsPrefix  = "PLUGIN_DOCKER"
+	HelmOptionsPrefix    = "PLUGIN_HELM"
+	TfOptionsPrefix      = "PLUGIN_TERRAFORM"
+)
+
+// UploadOptions are the settings of jf rt upload.
+type UploadOptions struct {
+	Source      string `split_words:"true"`
+	Target      string `split_words:"true"`
+	Spec        string `split_words:"true"`
+	SpecVars    string `split_words:"true"`
+	TargetProps string `split_words:"true"`
+	Flat        bool   `split_words:"true"`
+	Threads     int    `split_words:"true"`
+	Retries     int    `split_words:"true"`
+	Insecure    bool   `split_words:"true"`
+}
+
+// NewUploadOptions returns the upload options of args, overridden by the
+// PLUGIN_UPLOAD_ environment variables.
+func NewUploadOptions(args Args) (UploadOptions, error) {
+	opts := UploadOptions{
+		Source:      args.Source,
+		Target:      args.Target,
+		Spec:        args.Spec,
+		SpecVars:    args.SpecVars,
+		TargetProps: args.TargetProps,
+		Flat:        parseBoolOrDefault(false, args.Flat),
+		Threads:     args.Threads,
+		Retries:     args.Retries,
+		Insecure:    parseBoolOrDefault(false, args.Insecure),
+	}
+	return opts, loadOptions(UploadOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the upload options.
+func (o UploadOptions) Validate() []error {
+	var problems []error
+	if o.Spec == "" {
+		if o.Source == "" {
+			problems = append(problems, errors.New("PLUGIN_SOURCE or PLUGIN_SPEC needs to be set"))
+		}
+		if o.Target == "" {
+			problems = append(problems, errors.New("PLUGIN_TARGET needs to be set with PLUGIN_SOURCE"))
+		}
+	}
+	problems = append(problems, notNegative("PLUGIN_THREADS", o.Threads)...)
+	problems = append(problems, notNegative("PLUGIN_RETRIES", o.Retries)...)
+	return problems
+}
+
+// Flags returns the transfer flags of the upload.
+func (o UploadOptions) Flags() []string {
+	var flags cmdFlags
+	flags.addInt("--retries", o.Retries)
+	flags = append(flags, "--flat="+strconv.FormatBool(o.Flat))
+	flags.addInt("--threads", o.Threads)
+	if o.Insecure {
+		flags = append(flags, "--insecure-tls")
+	}
+	return flags
+}
+
+// Paths returns the spec, or the target properties, source and target of
+// the upload, which jf expects after all other flags.
+func (o UploadOptions) Paths() []string {
+	var paths cmdFlags
+	if o.Spec != "" {
+		paths.addString("--spec", o.Spec)
+		paths.addString("--spec-vars", o.SpecVars)
+		return paths
+	}
+	paths.addString("--target-props", filterTargetProps(o.TargetProps))
+	return append(paths, o.Source, o.Target)
+}
+
+// MavenOptions are the settings of jf mvn-config and jf mvn.
+type MavenOptions struct {
+	Goals               string   `split_words:"true"`
+	PomFile             string   `split_words:"true"`
+	ResolverId          string   `split_words:"true"`
+	DeployerId          string   `split_words:"true"`
+	ResolveReleaseRepo  string   `split_words:"true"`
+	ResolveSnapshotRepo string   `split_words:"true"`
+	DeployReleaseRepo   string   `split_words:"true"`
+	DeploySnapshotRepo  string   `split_words:"true"`
+	ServerIdDeploy      string   `split_words:"true"`
+	IncludePatterns     []string `split_words:"true"`
+	ExcludePatterns     []string `split_words:"true"`
+	Global              bool     `split_words:"true"`
+	UseWrapper          bool     `split_words:"true"`
+	DetailedSummary     bool     `split_words:"true"`
+	Format              string   `split_words:"true"`
+	Insecure            bool     `split_words:"true"`
+	Scan                bool     `split_words:"true"`
+	Threads             int      `split_words:"true"`
+}
+
+// NewMavenOptions returns the maven options of args, overridden by the
+// PLUGIN_MAVEN_ environment variables.
+func NewMavenOptions(args Args) (MavenOptions, error) {
+	opts := MavenOptions{
+		Goals:               args.MvnGoals,
+		PomFile:             args.MvnPomFile,
+		ResolverId:          args.ResolverId,
+		DeployerId:          args.DeployerId,
+		ResolveReleaseRepo:  args.ResolveReleaseRepo,
+		ResolveSnapshotRepo: args.ResolveSnapshotRepo,
+		DeployReleaseRepo:   args.DeployReleaseRepo,
+		DeploySnapshotRepo:  args.DeploySnapshotRepo,
+		ServerIdDeploy:      args.ServerIdDeploy,
+		IncludePatterns:     splitList(args.IncludePatterns),
+		ExcludePatterns:     splitList(args.ExcludePatterns),
+		Global:              args.Global,
+		UseWrapper:          args.UseWrapper,
+		DetailedSummary:     args.DetailedSummary,
+		Format:              args.Format,
+		Insecure:            parseBoolOrDefault(false, args.Insecure),
+		Scan:                args.Scan,
+		Threads:             args.Threads,
+	}
+	return opts, loadOptions(MavenOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the maven options.
+func (o MavenOptions) Validate() []error {
+	return append(notNegative("PLUGIN_THREADS", o.Threads), checkFormat(o.Format)...)
+}
+
+// ValidateBuild returns the problems of the maven options for running goals.
+func (o MavenOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if strings.TrimSpace(o.Goals) == "" {
+		problems = append(problems, errors.New("PLUGIN_GOALS needs to be set"))
+	}
+	return problems
+}
+
+// setNonInteractiveDefaults fills in the mvn-config settings jf would
+// otherwise prompt for.
+func (o *MavenOptions) setNonInteractiveDefaults() {
+	o.Global = true
+	if o.ServerIdDeploy == "" {
+		o.ServerIdDeploy = o.ResolverId
+	}
+	// Must set both release and snapshot repos to prevent errors
+	o.ResolveReleaseRepo = valueOrDefault(o.ResolveReleaseRepo, "libs-release")
+	o.ResolveSnapshotRepo = valueOrDefault(o.ResolveSnapshotRepo, "libs-snapshot")
+	o.DeployReleaseRepo = valueOrDefault(o.DeployReleaseRepo, "libs-release-local")
+	o.DeploySnapshotRepo = valueOrDefault(o.DeploySnapshotRepo, "libs-snapshot-local")
+}
+
+// ConfigFlags returns the flags of jf mvn-config.
+func (o MavenOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addList("--exclude-patterns", o.ExcludePatterns, ";")
+	flags.addBool("--global", o.Global)
+	flags.addList("--include-patterns", o.IncludePatterns, ";")
+	flags.addString("--repo-deploy-releases", o.DeployReleaseRepo)
+	flags.addString("--repo-deploy-snapshots", o.DeploySnapshotRepo)
+	flags.addString("--repo-resolve-releases", o.ResolveReleaseRepo)
+	flags.addString("--repo-resolve-snapshots", o.ResolveSnapshotRepo)
+	flags.addString("--server-id-deploy", o.ServerIdDeploy)
+	flags.addString("--server-id-resolve", o.ResolverId)
+	flags.addBool("--use-wrapper", o.UseWrapper)
+	return flags
+}
+
+// RunFlags returns the flags of jf mvn besides the build name and number.
+func (o MavenOptions) RunFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--detailed-summary", o.DetailedSummary)
+	flags.addString("--format", o.Format)
+	flags.addBool("--insecure-tls", o.Insecure)
+	flags.addBool("--scan", o.Scan)
+	flags.addInt("--threads", o.Threads)
+	return flags
+}
+
+// GradleOptions are the settings of jf gradle-config and jf gradle.
+type GradleOptions struct {
+	Tasks               string `split_words:"true"`
+	BuildFile           string `split_words:"true"`
+	ResolverId          string `split_words:"true"`
+	DeployerId          string `split_words:"true"`
+	RepoDeploy          string `split_words:"true"`
+	RepoResolve         string `split_words:"true"`
+	ServerIdDeploy      string `split_words:"true"`
+	ServerIdResolve     string `split_words:"true"`
+	DeployIvyDesc       *bool  `split_words:"true"`
+	DeployMavenDesc     *bool  `split_words:"true"`
+	IvyArtifactsPattern string `split_words:"true"`
+	IvyDescPattern      string `split_words:"true"`
+	Global              bool   `split_words:"true"`
+	UseWrapper          bool   `split_words:"true"`
+	UsesPlugin          bool   `split_words:"true"`
+	DetailedSummary     bool   `split_words:"true"`
+	Format              string `split_words:"true"`
+	Scan                bool   `split_words:"true"`
+	Threads             int    `split_words:"true"`
+}
+
+// NewGradleOptions returns the gradle options of args, overridden by the
+// PLUGIN_GRADLE_ environment variables.
+func NewGradleOptions(args Args) (GradleOptions, error) {
+	opts := GradleOptions{
+		Tasks:               args.GradleTasks,
+		BuildFile:           args.BuildFile,
+		ResolverId:          args.ResolverId,
+		DeployerId:          args.DeployerId,
+		RepoDeploy:          args.RepoDeploy,
+		RepoResolve:         args.RepoResolve,
+		ServerIdDeploy:      args.ServerIdDeploy,
+		ServerIdResolve:     args.ServerIdResolve,
+		DeployIvyDesc:       args.DeployIvyDesc,
+		DeployMavenDesc:     args.DeployMavenDesc,
+		IvyArtifactsPattern: args.IvyArtifactsPattern,
+		IvyDescPattern:      args.IvyDescPattern,
+		Global:              args.Global,
+		UseWrapper:          args.UseWrapper,
+		UsesPlugin:          args.UsesPlugin,
+		DetailedSummary:     args.DetailedSummary,
+		Format:              args.Format,
+		Scan:                args.Scan,
+		Threads:             args.Threads,
+	}
+	return opts, loadOptions(GradleOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the gradle options.
+func (o GradleOptions) Validate() []error {
+	return append(notNegative("PLUGIN_THREADS", o.Threads), checkFormat(o.Format)...)
+}
+
+// ValidateBuild returns the problems of the gradle options for running
+// tasks.
+func (o GradleOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if strings.TrimSpace(o.Tasks) == "" {
+		problems = append(problems, errors.New("PLUGIN_TASKS needs to be set"))
+	}
+	return problems
+}
+
+// setNonInteractiveDefaults fills in the gradle-config settings jf would
+// otherwise prompt for.
+func (o *GradleOptions) setNonInteractiveDefaults() {
+	o.Global = true
+	o.ServerIdResolve = valueOrDefault(o.ServerIdResolve, o.ResolverId)
+	o.ServerIdDeploy = valueOrDefault(o.ServerIdDeploy, o.ResolverId)
+	o.RepoResolve = valueOrDefault(o.RepoResolve, "libs-release")
+	o.RepoDeploy = valueOrDefault(o.RepoDeploy, "libs-release-local")
+	// Use maven-style plugin to enable dependency resolution
+	o.UsesPlugin = true
+}
+
+// ConfigFlags returns the flags of jf gradle-config.
+func (o GradleOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addOptionalBool("--deploy-ivy-desc", o.DeployIvyDesc)
+	flags.addOptionalBool("--deploy-maven-desc", o.DeployMavenDesc)
+	flags.addBool("--global", o.Global)
+	flags.addString("--ivy-artifacts-pattern", o.IvyArtifactsPattern)
+	flags.addString("--ivy-desc-pattern", o.IvyDescPattern)
+	flags.addString("--repo-deploy", o.RepoDeploy)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-deploy", o.ServerIdDeploy)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	flags.addBool("--use-wrapper", o.UseWrapper)
+	flags.addBool("--uses-plugin", o.UsesPlugin)
+	return flags
+}
+
+// RunFlags returns the flags of jf gradle besides the build name and number.
+func (o GradleOptions) RunFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--detailed-summary", o.DetailedSummary)
+	flags.addString("--format", o.Format)
+	flags.addBool("--scan", o.Scan)
+	flags.addInt("--threads", o.Threads)
+	return flags
+}
+
+// PromoteOptions are the settings of jf rt build-promote.
+type PromoteOptions struct {
+	Target string `split_words:"true"`
+	Copy   *bool  `split_words:"true"`
+}
+
+// NewPromoteOptions returns the promotion options of args, overridden by
+// the PLUGIN_PROMOTE_ environment variables.
+func NewPromoteOptions(args Args) (PromoteOptions, error) {
+	opts := PromoteOptions{Target: args.Target}
+	if args.Copy != "" {
+		copyArtifacts, err := parseBoolSetting("PLUGIN_COPY", args.Copy)
+		if err != nil {
+			return opts, err
+		}
+		opts.Copy = &copyArtifacts
+	}
+	return opts, loadOptions(PromoteOptionsPrefix, &opts)
+}
+
+// Validate returns the problems of the promotion options.
+func (o PromoteOptions) Validate() []error {
+	if o.Target == "" {
+		return []error{errors.New("PLUGIN_TARGET needs to be set")}
+	}
+	return nil
+}
+
+// Flags returns the flags of the promotion.
+func (o PromoteOptions) Flags() []string {
+	var flags cmdFlags
+	flags.addOptionalBool("--copy", o.Copy)
+	return flags
+}
+
+// DiscardOptions are the settings of jf rt build-discard.
+type DiscardOptions struct {
+	Async           bool     `split_words:"true"`
+	DeleteArtifacts bool     `split_words:"true"`
+	ExcludeBuilds   []string `split_words:"true"`
+	MaxBuilds       int      `split_words:"true"`
+	MaxDays         int      `split_words:"true"`
+}
+
+// NewDiscardOptions returns the build discard options of args, overridden
+// by the PLUGIN_DISCARD_ environment variables.
+func NewDiscardOptions(args Args) (DiscardOptions, error) {
+	var opts DiscardOptions
+	var problems []error
+	var err error
+	if opts.Async, err = parseBoolSetting("PLUGIN_ASYNC", args.Async); err != nil {
+		problems = append(problems, err)
+	}
+	if opts.DeleteArtifacts, err = parseBoolSetting("PLUGIN_DELETE_ARTIFACTS", args.DeleteArtifacts); err != nil {
+		problems = append(problems, err)
+	}
+	if opts.MaxBuilds, err = parseIntSetting("PLUGIN_MAX_BUILDS", args.MaxBuilds); err != nil {
+		problems = append(problems, err)
+	}
+	if opts.MaxDays, err = parseIntSetting("PLUGIN_MAX_DAYS", args.MaxDays); err != nil {
+		problems = append(problems, err)
+	}
+	opts.ExcludeBuilds = splitList(args.ExcludeBuilds)
+	if len(problems) > 0 {
+		return opts, errors.Join(problems...)
+	}
+	return opts, loadOptions(DiscardOptionsPrefix, &opts)
+}
+
+// IsSet reports whether any discard rule is configured.
+func (o DiscardOptions) IsSet() bool {
+	return o.Async || o.DeleteArtifacts || len(o.ExcludeBuilds) > 0 || o.MaxBuilds != 0 || o.MaxDays != 0
+}
+
+// Validate returns the problems of the build discard options.
+func (o DiscardOptions) Validate() []error {
+	return append(notNegative("PLUGIN_MAX_BUILDS", o.MaxBuilds), notNegative("PLUGIN_MAX_DAYS", o.MaxDays)...)
+}
+
+// Flags returns the flags of the build discard.
+func (o DiscardOptions) Flags() []string {
+	var flags cmdFlags
+	flags.addBool("--async", o.Async)
+	flags.addBool("--delete-artifacts", o.DeleteArtifacts)
+	flags.addList("--exclude-builds", o.ExcludeBuilds, ",")
+	flags.addInt("--max-builds", o.MaxBuilds)
+	flags.addInt("--max-days", o.MaxDays)
+	return flags
+}
+
+// validateOptions returns an RtCommand.Validate hook loading the options of
+// a command and checking them with validate.
+func validateOptions[T any](load func(Args) (T, error), validate func(T) []error) func(Args) []error {
+	return func(args Args) []error {
+		opts, err := load(args)
+		if err != nil {
+			return []error{err}
+		}
+		return validate(opts)
+	}
+}
+
+// loadOptions overrides opts with the environment variables named after its
+// fields under prefix, such as PLUGIN_UPLOAD_THREADS for UploadOptions.Threads.
+// Unset variables leave the fields unchanged.
+func loadOptions(prefix string, opts interface{}) error {
+	if err := envconfig.Process(prefix, opts); err != nil {
+		return fmt.Errorf("invalid %s settings: %w", prefix, err)
+	}
+	return nil
+}
+
+// buildFlags returns the flags recording a command in the build info of the
+// step.
+func buildFlags(args Args) []string {
+	var flags cmdFlags
+	flags.addString("--build-name", args.BuildName)
+	flags.addString("--build-number", args.BuildNumber)
+	return flags
+}
+
+// cmdFlags collects the flags of a jf command, skipping unset options.
+type cmdFlags []string
+
+func (f *cmdFlags) addString(name, value string) {
+	if value != "" {
+		*f = append(*f, name+"="+value)
+	}
+}
+
+func (f *cmdFlags) addInt(name string, value int) {
+	if value != 0 {
+		*f = append(*f, name+"="+strconv.Itoa(value))
+	}
+}
+
+func (f *cmdFlags) addBool(name string, value bool) {
+	if value {
+		*f = append(*f, name+"=true")
+	}
+}
+
+func (f *cmdFlags) addOptionalBool(name string, value *bool) {
+	if value != nil {
+		*f = append(*f, name+"="+strconv.FormatBool(*value))
+	}
+}
+
+func (f *cmdFlags) addList(name string, values []string, sep string) {
+	if len(values) > 0 {
+		*f = append(*f, name+"="+strings.Join(values, sep))
+	}
+}
+
+// splitList splits a comma or semicolon separated setting.
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// splitArgs splits a setting holding command line arguments like a shell
+// does, without expanding variables. Arguments are separated by whitespace
+// and may be quoted with single or double quotes. Outside single quotes a
+// backslash escapes a following quote, whitespace or backslash and is kept
+// as is otherwise, so that Windows paths need no escaping.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && quote != '\'' && i+1 < len(runes) && isEscapable(runes[i+1]):
+			i++
+			arg.WriteRune(runes[i])
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+func isEscapable(r rune) bool {
+	return r == '\'' || r == '"' || r == '\\' || unicode.IsSpace(r)
+}
+
+func parseBoolSetting(name, s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q, must be true or false", name, s)
+	}
+	return value, nil
+}
+
+func parseIntSetting(name, s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q, must be a number", name, s)
+	}
+	return value, nil
+}
+
+func notNegative(name string, value int) []error {
+	if value < 0 {
+		return []error{fmt.Errorf("%s must not be negative", name)}
+	}
+	return nil
+}
+
+func checkFormat(format string) []error {
+	switch format {
+	case "", "table", "json", "simple-json", "sarif":
+		return nil
+	}
+	return []error{fmt.Errorf("PLUGIN_FORMAT %q is not one of table, json, simple-json, sarif", format)}
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewUploadOptionsPrefixedEnvWins(t *testing.T) {
+	t.Setenv("PLUGIN_UPLOAD_TARGET", "libs-snapshot-local/app/")
+	t.Setenv("PLUGIN_UPLOAD_THREADS", "8")
+	t.Setenv("PLUGIN_UPLOAD_FLAT", "true")
+
+	opts, err := NewUploadOptions(Args{Source: "dist/app.jar", Target: "libs-release-local/app/", Threads: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := UploadOptions{Source: "dist/app.jar", Target: "libs-snapshot-local/app/", Flat: true, Threads: 8}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("Expected %+v, got %+v", want, opts)
+	}
+	if got := strings.Join(append(opts.Flags(), opts.Paths()...), " "); got !=
+		"--flat=true --threads=8 dist/app.jar libs-snapshot-local/app/" {
+		t.Errorf("Unexpected upload flags: %s", got)
+	}
+}
+
+func TestNewOptionsInvalidEnv(t *testing.T) {
+	t.Setenv("PLUGIN_DISCARD_MAX_DAYS", "a week")
+	_, err := NewDiscardOptions(Args{})
+	if err == nil || !strings.Contains(err.Error(), "invalid PLUGIN_DISCARD settings") {
+		t.Errorf("Expected an invalid settings error, got %v", err)
+	}
+}
+
+func TestPromoteOptionsDoNotReadOtherCommands(t *testing.T) {
+	t.Setenv("PLUGIN_UPLOAD_TARGET", "libs-release-local/app/")
+	t.Setenv("PLUGIN_PROMOTE_COPY", "true")
+
+	args := Args{URL: RtUrlTestStr, AccessToken: RtAccessToken, BuildName: RtBuildName, BuildNumber: RtBuildNumber}
+	if _, err := GetPromoteCommandArgs(args); err == nil || err.Error() != "PLUGIN_TARGET needs to be set" {
+		t.Errorf("Expected the upload target to be ignored, got %v", err)
+	}
+
+	t.Setenv("PLUGIN_PROMOTE_TARGET", "libs-release")
+	cmdList, err := GetPromoteCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "rt build-promote --copy=true --server-id=tmpServerId t2 v1.0 libs-release"
+	if got := strings.Join(cmdList[1], " "); got != want {
+		t.Errorf("Expected: %s\nGot: %s", want, got)
+	}
+}
+
+func TestMavenOptionsFlags(t *testing.T) {
+	opts, err := NewMavenOptions(Args{
+		MvnGoals:        "clean install",
+		IncludePatterns: "*.jar;*.pom",
+		UseWrapper:      true,
+		Threads:         4,
+		Insecure:        "true",
+		ResolverId:      RtRslvId,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantConfig := "--include-patterns=*.jar;*.pom --server-id-resolve=" + RtRslvId + " --use-wrapper=true"
+	if got := strings.Join(opts.ConfigFlags(), " "); got != wantConfig {
+		t.Errorf("Expected config flags: %s\nGot: %s", wantConfig, got)
+	}
+	if got := strings.Join(opts.RunFlags(), " "); got != "--insecure-tls=true --threads=4" {
+		t.Errorf("Unexpected run flags: %s", got)
+	}
+}
+
+func TestDiscardOptions(t *testing.T) {
+	opts, err := NewDiscardOptions(Args{Async: "true", ExcludeBuilds: "1.0,2.0", MaxDays: "30"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "--async=true --exclude-builds=1.0,2.0 --max-days=30"
+	if got := strings.Join(opts.Flags(), " "); got != want {
+		t.Errorf("Expected: %s\nGot: %s", want, got)
+	}
+
+	if _, err := NewDiscardOptions(Args{MaxBuilds: "five"}); err == nil ||
+		err.Error() != `invalid PLUGIN_MAX_BUILDS "five", must be a number` {
+		t.Errorf("Expected an invalid number error, got %v", err)
+	}
+	if problems := (DiscardOptions{MaxBuilds: -1}).Validate(); len(problems) != 1 {
+		t.Errorf("Expected a negative max builds problem, got %v", problems)
+	}
+	if IsBuildDiscardArgs(Args{Async: "false"}) {
+		t.Errorf("Expected async=false alone not to discard builds")
+	}
+}
+
+func TestValidateOptionsHooks(t *testing.T) {
+	tests := []struct {
+		command string
+		args    Args
+		want    string
+	}{
+		{"build", Args{BuildTool: "maven", Threads: -1}, "PLUGIN_THREADS must not be negative"},
+		{"build", Args{BuildTool: "maven"}, "PLUGIN_GOALS needs to be set"},
+		{"build", Args{BuildTool: "gradle", Format: "xml"}, `PLUGIN_FORMAT "xml" is not one of`},
+		{"build", Args{BuildTool: "gradle"}, "PLUGIN_TASKS needs to be set"},
+		{"promote", Args{Copy: "yes"}, `invalid PLUGIN_COPY "yes", must be true or false`},
+	}
+	for _, tc := range tests {
+		rtCmd, err := LookupRtCommand(tc.args.BuildTool, tc.command)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got []string
+		for _, problem := range rtCmd.Validate(tc.args) {
+			got = append(got, problem.Error())
+		}
+		if !strings.Contains(strings.Join(got, "\n"), tc.want) {
+			t.Errorf("%s %s: expected a problem containing %q, got %q", tc.args.BuildTool, tc.command, tc.want, got)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"  clean   install ", []string{"clean", "install"}},
+		{`clean install "-Dmsg=hello world"`, []string{"clean", "install", "-Dmsg=hello world"}},
+		{`-Pname='it is' -Dq="say \"hi\""`, []string{"-Pname=it is", `-Dq=say "hi"`}},
+		{`a\ b ''`, []string{"a b", ""}},
+		{`-Dout=C:\build\out`, []string{`-Dout=C:\build\out`}},
+	}
+	for _, tc := range tests {
+		got, err := splitArgs(tc.input)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", tc.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("For %q expected %q, got %q", tc.input, tc.want, got)
+		}
+	}
+
+	if _, err := splitArgs(`clean "install`); err == nil {
+		t.Errorf("Expected an error for an unterminated quote")
+	}
+}
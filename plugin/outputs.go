@@ -0,0 +1,232 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Step output names written to the output file.
+const (
+	OutputUploadedArtifactCount = "UPLOADED_ARTIFACT_COUNT"
+	OutputFailedArtifactCount   = "FAILED_ARTIFACT_COUNT"
+	OutputTargetPaths           = "TARGET_PATHS"
+	OutputBuildName             = "BUILD_NAME"
+	OutputBuildNumber           = "BUILD_NUMBER"
+	OutputBuildInfoURL          = "BUILD_INFO_URL"
+	OutputScanVerdict           = "SCAN_VERDICT"
+	OutputScanViolations        = "SCAN_VIOLATIONS"
+	OutputPromotedTo            = "PROMOTED_TO"
+)
+
+// outputFileEnvVars are the environment variables naming the file step
+// outputs are written to, in order of precedence.
+var outputFileEnvVars = []string{"DRONE_OUTPUT", "HARNESS_OUTPUT"}
+
+// jf exits with code 3 when a build scan finds violations failing the build.
+const scanFailBuildExitCode = 3
+
+var buildInfoURLPattern = regexp.MustCompile(`Browse it in Artifactory under (\S+)`)
+
+// StepOutputs collects the key=value outputs of a plugin run so that later
+// pipeline steps can consume them.
+type StepOutputs struct {
+	path   string
+	keys   []string
+	values map[string]string
+}
+
+// NewStepOutputs returns the outputs for a run, writing to the file named by
+// DRONE_OUTPUT or HARNESS_OUTPUT. Outputs are discarded when neither is set.
+func NewStepOutputs() *StepOutputs {
+	outputs := &StepOutputs{values: map[string]string{}}
+	for _, name := range outputFileEnvVars {
+		if path := os.Getenv(name); path != "" {
+			outputs.path = path
+			break
+		}
+	}
+	return outputs
+}
+
+// Enabled reports whether the outputs are written to a file.
+func (o *StepOutputs) Enabled() bool {
+	return o.path != ""
+}
+
+// Set records an output, replacing a previous value with the same name.
+func (o *StepOutputs) Set(name, value string) {
+	if _, ok := o.values[name]; !ok {
+		o.keys = append(o.keys, name)
+	}
+	o.values[name] = value
+}
+
+// Get returns a recorded output.
+func (o *StepOutputs) Get(name string) string {
+	return o.values[name]
+}
+
+// Collect records the outputs derived from a jf command and its result.
+func (o *StepOutputs) Collect(args Args, cmdArgs []string, result ExecResult, err error) {
+	subCmd := jfSubcommand(cmdArgs)
+
+	switch subCmd {
+	case "rt u", "rt upload", "mvn", "gradle", "npm", "go-publish", "terraform":
+		if err == nil {
+			o.collectTransferSummary(result.Stdout)
+		}
+		o.setBuild(args)
+	case "rt build-publish", "rt bp":
+		if match := buildInfoURLPattern.FindStringSubmatch(result.Stdout + result.Stderr); match != nil {
+			o.Set(OutputBuildInfoURL, match[1])
+		}
+		o.setBuild(args)
+	case "build-scan", "bs":
+		o.collectScanSummary(result, err)
+		o.setBuild(args)
+	case "rt build-promote", "rt bpr":
+		if opts, optsErr := NewPromoteOptions(args); err == nil && optsErr == nil {
+			o.Set(OutputPromotedTo, opts.Target)
+		}
+		o.setBuild(args)
+	}
+}
+
+// Write appends the recorded outputs to the output file.
+func (o *StepOutputs) Write() error {
+	if !o.Enabled() || len(o.keys) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	for _, name := range o.keys {
+		value := strings.NewReplacer("\r", " ", "\n", " ").Replace(o.values[name])
+		if _, err := fmt.Fprintf(file, "%s=%s\n", name, value); err != nil {
+			return fmt.Errorf("failed to write output file: %v", err)
+		}
+	}
+	logrus.Printf("Wrote %d step output(s) to %s\n", len(o.keys), o.path)
+	return nil
+}
+
+// add increments a numeric output, for runs transferring artifacts with
+// several commands.
+func (o *StepOutputs) add(name string, n int) {
+	if prev, err := strconv.Atoi(o.Get(name)); err == nil {
+		n += prev
+	}
+	o.Set(name, strconv.Itoa(n))
+}
+
+func (o *StepOutputs) setBuild(args Args) {
+	if args.BuildName != "" {
+		o.Set(OutputBuildName, args.BuildName)
+	}
+	if args.BuildNumber != "" {
+		o.Set(OutputBuildNumber, args.BuildNumber)
+	}
+}
+
+// transferSummary is the JSON summary jf prints after uploading or
+// deploying artifacts. Files is only present with --detailed-summary.
+type transferSummary struct {
+	Status string `json:"status"`
+	Totals struct {
+		Success int `json:"success"`
+		Failure int `json:"failure"`
+	} `json:"totals"`
+	Files []struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	} `json:"files"`
+}
+
+func (o *StepOutputs) collectTransferSummary(stdout string) {
+	var summary transferSummary
+	if !unmarshalJSONSummary(stdout, &summary) || summary.Status == "" {
+		return
+	}
+
+	o.add(OutputUploadedArtifactCount, summary.Totals.Success)
+	o.add(OutputFailedArtifactCount, summary.Totals.Failure)
+
+	var targets []string
+	if prev := o.Get(OutputTargetPaths); prev != "" {
+		targets = append(targets, prev)
+	}
+	for _, file := range summary.Files {
+		targets = append(targets, file.Target)
+	}
+	if len(targets) > 0 {
+		o.Set(OutputTargetPaths, strings.Join(targets, ","))
+	}
+}
+
+// scanSummary is the JSON output of jf build-scan --format=json.
+type scanSummary []struct {
+	Violations []json.RawMessage `json:"violations"`
+}
+
+func (o *StepOutputs) collectScanSummary(result ExecResult, err error) {
+	switch {
+	case err == nil:
+		o.Set(OutputScanVerdict, "passed")
+	case result.ExitCode == scanFailBuildExitCode:
+		o.Set(OutputScanVerdict, "failed")
+	default:
+		o.Set(OutputScanVerdict, "error")
+		return
+	}
+
+	var summary scanSummary
+	if unmarshalJSONSummary(result.Stdout, &summary) {
+		violations := 0
+		for _, scan := range summary {
+			violations += len(scan.Violations)
+		}
+		o.Set(OutputScanViolations, strconv.Itoa(violations))
+	}
+}
+
+// unmarshalJSONSummary decodes the last JSON document printed by jf at the
+// start of a line, ignoring the build output printed before it, such as the
+// [INFO] lines of Maven.
+func unmarshalJSONSummary(stdout string, v interface{}) bool {
+	for end := len(stdout); end > 0; {
+		start := strings.LastIndex(stdout[:end], "\n") + 1
+		if line := stdout[start:end]; strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[") {
+			var doc json.RawMessage
+			if json.NewDecoder(strings.NewReader(stdout[start:])).Decode(&doc) == nil {
+				return json.Unmarshal(doc, v) == nil
+			}
+		}
+		end = start - 1
+	}
+	return false
+}
+
+// jfSubcommand returns the jf command name of cmdArgs, such as "rt u" or
+// "build-scan", skipping the jf binary.
+func jfSubcommand(cmdArgs []string) string {
+	if len(cmdArgs) > 0 && cmdArgs[0] == getJfrogBin() {
+		cmdArgs = cmdArgs[1:]
+	}
+	if len(cmdArgs) == 0 {
+		return ""
+	}
+	if cmdArgs[0] == "rt" && len(cmdArgs) > 1 {
+		return "rt " + cmdArgs[1]
+	}
+	return cmdArgs[0]
+}
@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecUploadWritesStepOutputs(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.env")
+	t.Setenv("DRONE_OUTPUT", outputFile)
+
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/*.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+	var uploadArgv []string
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		switch jfSubcommand(argv) {
+		case "rt u":
+			uploadArgv = argv
+			return ExecResult{Stdout: `{
+  "status": "success",
+  "totals": {"success": 2, "failure": 0},
+  "files": [
+    {"source": "dist/a.jar", "target": "libs-release-local/app/a.jar"},
+    {"source": "dist/b.jar", "target": "libs-release-local/app/b.jar"}
+  ]
+}`}, nil
+		case "rt build-publish":
+			return ExecResult{Stderr: "[Info] Build info successfully deployed. Browse it in Artifactory under " +
+				"https://artifactory.test.io/ui/builds/t2/v1.0/1/published\n"}, nil
+		}
+		return ExecResult{}, nil
+	})
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if uploadArgv[len(uploadArgv)-1] != "--detailed-summary" {
+		t.Errorf("Expected upload to request a detailed summary, got %q", uploadArgv)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Unexpected error reading outputs: %v", err)
+	}
+	want := []string{
+		"UPLOADED_ARTIFACT_COUNT=2",
+		"FAILED_ARTIFACT_COUNT=0",
+		"TARGET_PATHS=libs-release-local/app/a.jar,libs-release-local/app/b.jar",
+		"BUILD_NAME=t2",
+		"BUILD_NUMBER=v1.0",
+		"BUILD_INFO_URL=https://artifactory.test.io/ui/builds/t2/v1.0/1/published",
+	}
+	got := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected outputs:\n%s\nGot:\n%s", strings.Join(want, "\n"), string(content))
+	}
+}
+
+func TestStepOutputsTransferSummaryAfterBuildOutput(t *testing.T) {
+	stdout := "[INFO] Scanning for projects...\n" +
+		"[INFO] BUILD SUCCESS\n" +
+		"{\n" +
+		"  \"status\": \"success\",\n" +
+		"  \"totals\": {\"success\": 3, \"failure\": 1},\n" +
+		"  \"files\": [{\"source\": \"app.jar\", \"target\": \"libs-release-local/app.jar\"}]\n" +
+		"}\n" +
+		"[Info] Deployment finished\n"
+
+	outputs := NewStepOutputs()
+	outputs.Collect(Args{}, []string{"jf", "mvn", "deploy"}, ExecResult{Stdout: stdout}, nil)
+	for name, want := range map[string]string{
+		OutputUploadedArtifactCount: "3",
+		OutputFailedArtifactCount:   "1",
+		OutputTargetPaths:           "libs-release-local/app.jar",
+	} {
+		if got := outputs.Get(name); got != want {
+			t.Errorf("Expected %s=%s, Got: %q", name, want, got)
+		}
+	}
+}
+
+func TestStepOutputsScanVerdict(t *testing.T) {
+	tests := []struct {
+		result      ExecResult
+		err         error
+		wantVerdict string
+		wantCount   string
+	}{
+		{ExecResult{Stdout: `[{"violations": []}]`}, nil, "passed", "0"},
+		{ExecResult{ExitCode: 3, Stdout: `[{"violations": [{"severity": "High"}, {"severity": "Low"}]}]`},
+			errors.New("exit status 3"), "failed", "2"},
+		{ExecResult{ExitCode: 1}, errors.New("exit status 1"), "error", ""},
+	}
+
+	args := Args{BuildName: RtBuildName, BuildNumber: RtBuildNumber}
+	for _, tc := range tests {
+		outputs := NewStepOutputs()
+		outputs.Collect(args, []string{"jf", "build-scan", RtBuildName, RtBuildNumber}, tc.result, tc.err)
+		if got := outputs.Get(OutputScanVerdict); got != tc.wantVerdict {
+			t.Errorf("Expected verdict %q, Got: %q", tc.wantVerdict, got)
+		}
+		if got := outputs.Get(OutputScanViolations); got != tc.wantCount {
+			t.Errorf("Expected %q violations, Got: %q", tc.wantCount, got)
+		}
+	}
+}
+
+func TestExecScanWritesViolations(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.env")
+	t.Setenv("DRONE_OUTPUT", outputFile)
+
+	args := Args{
+		Command:     "scan",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Format:      "json",
+	}
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		if jfSubcommand(argv) != "build-scan" {
+			return ExecResult{}, nil
+		}
+		// jf prints a table unless JSON is requested
+		if argv[len(argv)-1] != "--format=json" {
+			return ExecResult{ExitCode: 3, Stdout: "| SEVERITY | COMPONENT |\n| High | lib |"}, errors.New("exit status 3")
+		}
+		return ExecResult{ExitCode: 3, Stdout: `[{"violations": [{"severity": "High"}]}]`}, errors.New("exit status 3")
+	})
+	if err := ExecWithExecutor(context.Background(), args, executor); err == nil {
+		t.Fatalf("Expected the failed scan to fail the step")
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Unexpected error reading outputs: %v", err)
+	}
+	for _, want := range []string{"SCAN_VERDICT=failed", "SCAN_VIOLATIONS=1"} {
+		if !strings.Contains(string(content), want+"\n") {
+			t.Errorf("Expected output %s, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestStepOutputsPromotedToPromoteTarget(t *testing.T) {
+	t.Setenv("PLUGIN_PROMOTE_TARGET", "libs-release")
+
+	outputs := NewStepOutputs()
+	args := Args{BuildName: RtBuildName, BuildNumber: RtBuildNumber, Target: "libs-snapshot-local/app/"}
+	outputs.Collect(args, []string{"jf", "rt", "build-promote", RtBuildName, RtBuildNumber, "libs-release"}, ExecResult{}, nil)
+	if got := outputs.Get(OutputPromotedTo); got != "libs-release" {
+		t.Errorf("Expected the promotion target, Got: %q", got)
+	}
+}
@@ -6,14 +6,15 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -34,11 +35,14 @@ type Args struct {
 	// Level defines the plugin log level.
 	Level string `envconfig:"PLUGIN_LOG_LEVEL"`
 
+	// ConfigFile is the YAML or JSON file read by LoadConfigFile.
+	ConfigFile string `envconfig:"PLUGIN_CONFIG_FILE"`
+
 	// TODO replace or remove
 	Username         string `envconfig:"PLUGIN_USERNAME"`
-	Password         string `envconfig:"PLUGIN_PASSWORD"`
-	APIKey           string `envconfig:"PLUGIN_API_KEY"`
-	AccessToken      string `envconfig:"PLUGIN_ACCESS_TOKEN"`
+	Password         string `envconfig:"PLUGIN_PASSWORD" secret:"true"`
+	APIKey           string `envconfig:"PLUGIN_API_KEY" secret:"true"`
+	AccessToken      string `envconfig:"PLUGIN_ACCESS_TOKEN" secret:"true"`
 	URL              string `envconfig:"PLUGIN_URL"`
 	Source           string `envconfig:"PLUGIN_SOURCE"`
 	Target           string `envconfig:"PLUGIN_TARGET"`
@@ -49,16 +53,63 @@ type Args struct {
 	SpecVars         string `envconfig:"PLUGIN_SPEC_VARS"`
 	TargetProps      string `envconfig:"PLUGIN_TARGET_PROPS"`
 	Insecure         string `envconfig:"PLUGIN_INSECURE"`
-	PEMFileContents  string `envconfig:"PLUGIN_PEM_FILE_CONTENTS"`
+	PEMFileContents  string `envconfig:"PLUGIN_PEM_FILE_CONTENTS" secret:"true"`
 	PEMFilePath      string `envconfig:"PLUGIN_PEM_FILE_PATH"`
 	BuildNumber      string `envconfig:"PLUGIN_BUILD_NUMBER"`
 	BuildName        string `envconfig:"PLUGIN_BUILD_NAME"`
 	PublishBuildInfo bool   `envconfig:"PLUGIN_PUBLISH_BUILD_INFO"`
+	AddGitInfo       bool   `envconfig:"PLUGIN_ADD_GIT_INFO"`
+	CollectEnv       bool   `envconfig:"PLUGIN_COLLECT_ENV"`
+	EnvInclude       string `envconfig:"PLUGIN_ENV_INCLUDE"`
+	EnvExclude       string `envconfig:"PLUGIN_ENV_EXCLUDE"`
 	EnableProxy      string `envconfig:"PLUGIN_ENABLE_PROXY"`
+	DryRun           bool   `envconfig:"PLUGIN_DRY_RUN"`
+	ValidateOnly     bool   `envconfig:"PLUGIN_VALIDATE_ONLY"`
+
+	// BuildNameTemplate and BuildNumberTemplate are text/template strings
+	// rendered against Pipeline when BuildName or BuildNumber is unset.
+	BuildNameTemplate   string `envconfig:"PLUGIN_BUILD_NAME_TEMPLATE"`
+	BuildNumberTemplate string `envconfig:"PLUGIN_BUILD_NUMBER_TEMPLATE"`
+
+	// LegacyShellExec runs the jf commands through sh -c or PowerShell
+	// instead of executing jf directly.
+	LegacyShellExec bool `envconfig:"PLUGIN_LEGACY_SHELL_EXEC"`
+
+	// Timeout bounds the whole plugin run, CommandTimeout every jf invocation.
+	Timeout        time.Duration `envconfig:"PLUGIN_TIMEOUT"`
+	CommandTimeout time.Duration `envconfig:"PLUGIN_COMMAND_TIMEOUT"`
+
+	// Retry policy applied to every jf invocation
+	CommandRetries     int           `envconfig:"PLUGIN_COMMAND_RETRIES"`
+	RetryBackoff       time.Duration `envconfig:"PLUGIN_RETRY_BACKOFF"`
+	RetryMaxBackoff    time.Duration `envconfig:"PLUGIN_RETRY_MAX_BACKOFF"`
+	RetryJitter        float64       `envconfig:"PLUGIN_RETRY_JITTER"`
+	RetryExitCodes     []int         `envconfig:"PLUGIN_RETRY_EXIT_CODES"`
+	RetryPatterns      []string      `envconfig:"PLUGIN_RETRY_PATTERNS"`
+	RetryNonIdempotent bool          `envconfig:"PLUGIN_RETRY_NON_IDEMPOTENT"`
+
+	// runID makes the temporary server ids registered by the run unique.
+	runID string
+	// workDir is the private directory of the run, removed when it ends.
+	workDir string
+	// gitDir is the directory holding the .git synthesized from the
+	// pipeline metadata, when the working directory is not a checkout.
+	gitDir string
+	// cliHomeDir is the JFrog CLI home of the run, empty when the plugin
+	// uses the JFROG_CLI_HOME_DIR of the environment.
+	cliHomeDir string
+	// dockerConfigDir holds the docker credentials of the registry logins
+	// of the run, empty when the docker config of the environment is used.
+	dockerConfigDir string
+	// npmrcPath is the npmrc pnpm resolves packages with, empty when pnpm
+	// uses the npmrc of the environment.
+	npmrcPath string
 
 	// RT commands
 	BuildTool string `envconfig:"PLUGIN_BUILD_TOOL"`
 	Command   string `envconfig:"PLUGIN_COMMAND"`
+	// Commands runs several commands in order, sharing their configuration.
+	Commands []string `envconfig:"PLUGIN_COMMANDS"`
 
 	// Mvn commands
 	ResolveReleaseRepo  string `envconfig:"PLUGIN_RESOLVE_RELEASE_REPO"`
@@ -77,6 +128,60 @@ type Args struct {
 	RepoDeploy  string `envconfig:"PLUGIN_REPO_DEPLOY"`
 	RepoResolve string `envconfig:"PLUGIN_REPO_RESOLVE"`
 
+	// Npm commands
+	InstallCommand string `envconfig:"PLUGIN_INSTALL_COMMAND"`
+	NpmArgs        string `envconfig:"PLUGIN_NPM_ARGS"`
+
+	// Yarn and pnpm commands
+	YarnArgs string `envconfig:"PLUGIN_YARN_ARGS"`
+	PnpmArgs string `envconfig:"PLUGIN_PNPM_ARGS"`
+
+	// Go commands
+	GoArgs  string `envconfig:"PLUGIN_GO_ARGS"`
+	Version string `envconfig:"PLUGIN_VERSION"`
+
+	// Python commands
+	PythonArgs string `envconfig:"PLUGIN_PYTHON_ARGS"`
+	DistDir    string `envconfig:"PLUGIN_DIST_DIR"`
+
+	// Dotnet and NuGet commands
+	DotnetArgs   string `envconfig:"PLUGIN_DOTNET_ARGS"`
+	Solution     string `envconfig:"PLUGIN_SOLUTION"`
+	NupkgPattern string `envconfig:"PLUGIN_NUPKG_PATTERN"`
+	NugetV2      *bool  `envconfig:"PLUGIN_NUGET_V2"`
+
+	// Docker commands
+	DockerImage string `envconfig:"PLUGIN_IMAGE"`
+	SkipLogin   bool   `envconfig:"PLUGIN_SKIP_LOGIN"`
+
+	// Helm commands
+	ChartPath string `envconfig:"PLUGIN_CHART_PATH"`
+	Reindex   bool   `envconfig:"PLUGIN_REINDEX"`
+
+	// Terraform commands
+	ModulesDir string `envconfig:"PLUGIN_MODULES_DIR"`
+	Namespace  string `envconfig:"PLUGIN_NAMESPACE"`
+	Provider   string `envconfig:"PLUGIN_PROVIDER"`
+	ModuleTag  string `envconfig:"PLUGIN_MODULE_TAG"`
+
+	// Build tool flags
+	DetailedSummary bool   `envconfig:"PLUGIN_DETAILED_SUMMARY"`
+	Format          string `envconfig:"PLUGIN_FORMAT"`
+	Scan            bool   `envconfig:"PLUGIN_SCAN"`
+	Global          bool   `envconfig:"PLUGIN_GLOBAL"`
+	UseWrapper      bool   `envconfig:"PLUGIN_USE_WRAPPER"`
+	ServerIdDeploy  string `envconfig:"PLUGIN_SERVER_ID_DEPLOY"`
+	ServerIdResolve string `envconfig:"PLUGIN_SERVER_ID_RESOLVE"`
+	IncludePatterns string `envconfig:"PLUGIN_INCLUDE_PATTERNS"`
+	ExcludePatterns string `envconfig:"PLUGIN_EXCLUDE_PATTERNS"`
+
+	// Gradle config flags, the descriptors are deployed unless set to false
+	DeployIvyDesc       *bool  `envconfig:"PLUGIN_DEPLOY_IVY_DESC"`
+	DeployMavenDesc     *bool  `envconfig:"PLUGIN_DEPLOY_MAVEN_DESC"`
+	IvyArtifactsPattern string `envconfig:"PLUGIN_IVY_ARTIFACTS_PATTERN"`
+	IvyDescPattern      string `envconfig:"PLUGIN_IVY_DESC_PATTERN"`
+	UsesPlugin          bool   `envconfig:"PLUGIN_USES_PLUGIN"`
+
 	// Upload Download commands
 	SpecPath string `envconfig:"PLUGIN_SPEC_PATH"`
 	Module   string `envconfig:"PLUGIN_MODULE"`
@@ -91,6 +196,7 @@ type Args struct {
 	Recursive         string `envconfig:"PLUGIN_RECURSIVE"`
 	Regexp            string `envconfig:"PLUGIN_REGEXP"`
 	DependencyPattern string `envconfig:"PLUGIN_DEPENDENCY"`
+	ServerId          string `envconfig:"PLUGIN_SERVER_ID"`
 
 	// Build Discard commands
 	Async           string `envconfig:"PLUGIN_ASYNC"`
@@ -102,11 +208,72 @@ type Args struct {
 
 // Exec executes the plugin.
 func Exec(ctx context.Context, args Args) error {
+	redactor := NewRedactor(args)
+	executor := &OSExecutor{
+		Stdout: redactor.Writer(os.Stdout),
+		Stderr: redactor.Writer(os.Stderr),
+	}
+	return ExecWithExecutor(ctx, args, executor)
+}
+
+// ExecWithExecutor executes the plugin, running every command with executor.
+// Secrets are redacted from the plugin logs and the returned error.
+func ExecWithExecutor(ctx context.Context, args Args, executor Executor) error {
+	redactor := NewRedactor(args)
+	defer installLogRedaction(redactor)()
+
+	return redactor.RedactError(execPlugin(ctx, args, executor))
+}
+
+func execPlugin(ctx context.Context, args Args, executor Executor) error {
+
+	if err := CheckFlagTables(); err != nil {
+		return err
+	}
+
+	if err := applyBuildDefaults(&args); err != nil {
+		return err
+	}
+
+	if isValidateOnly(args) {
+		err := ValidateArgs(args)
+		logValidation(err)
+		return err
+	}
+
+	if _, err := NewRetryPolicy(args); err != nil {
+		return err
+	}
+
+	if args.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+		defer cancel()
+	}
+
+	args.runID = newRunID()
+	if !args.DryRun {
+		cleanup, err := prepareRunDir(&args)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if err := prepareGitInfo(&args); err != nil {
+			return err
+		}
+		if err := prepareDockerConfig(&args); err != nil {
+			return err
+		}
+		if err := preparePnpmRegistry(&args); err != nil {
+			return err
+		}
+	}
 
 	logrus.Println("Checking RT commands")
-	if args.BuildTool != "" || args.Command != "" {
+	if args.BuildTool != "" || args.Command != "" || len(args.Commands) > 0 {
 		logrus.Println("Handling rt command handleRtCommand")
-		return HandleRtCommands(args)
+		return HandleRtCommands(ctx, args, executor)
 	}
 
 	enableProxy := parseBoolOrDefault(false, args.EnableProxy)
@@ -115,161 +282,187 @@ func Exec(ctx context.Context, args Args) error {
 		setSecureConnectProxies()
 	}
 
-	// write code here
-	if args.URL == "" {
-		return fmt.Errorf("JFrog Artifactory URL must be set, or anonymous access is not permitted")
+	cmdList, err := GetUploadCommandArgs(args)
+	if err != nil {
+		return err
 	}
 
-	cmdArgs := []string{getJfrogBin(), "rt", "u", fmt.Sprintf("--url %s", args.URL)}
-	if args.Retries != 0 {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--retries=%d", args.Retries))
+	outputs := NewStepOutputs()
+	if outputs.Enabled() {
+		// the detailed summary lists the target path of every uploaded file
+		last := len(cmdList) - 1
+		cmdList[last] = append(cmdList[last], "--detailed-summary")
 	}
 
-	// Set authentication params
-	cmdArgs, error := setAuthParams(cmdArgs, args)
-	if error != nil {
-		return error
+	// Call publishBuildInfo if PLUGIN_PUBLISH_BUILD_INFO is set to true
+	var publishCmdArgs []string
+	if args.PublishBuildInfo {
+		publishCmdArgs, err = GetPublishBuildInfoCommandArgs(args)
+		if err != nil {
+			return err
+		}
+		serverCmdArgs, err := GetPublishBuildInfoServerCommandArgs(args)
+		if err != nil {
+			return err
+		}
+		cmdList = append(cmdList, serverCmdArgs)
 	}
 
-	flat := parseBoolOrDefault(false, args.Flat)
-	cmdArgs = append(cmdArgs, fmt.Sprintf("--flat=%s", strconv.FormatBool(flat)))
-
-	if args.Threads > 0 {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--threads=%d", args.Threads))
-	}
-	// Set insecure flag
-	insecure := parseBoolOrDefault(false, args.Insecure)
-	if insecure {
-		cmdArgs = append(cmdArgs, "--insecure-tls")
+	if args.DryRun {
+		if publishCmdArgs != nil {
+			cmdList = append(cmdList, publishCmdArgs)
+		}
+		PrintDryRunPlan(os.Stdout, args, withBuildInfoSteps(args, jfCommands(cmdList)))
+		return nil
 	}
 
-	// Add --build-number and --build-name flags if provided
-	if args.BuildNumber != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-number=%s", args.BuildNumber))
+	if err := WriteKnownGoodServerCertsForTls(args); err != nil {
+		return err
 	}
-	if args.BuildName != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-name='%s'", args.BuildName))
-	}
-
-	// create pem file
-	if args.PEMFileContents != "" && !insecure {
-		var path string
-		// figure out path to write pem file
-		if args.PEMFilePath == "" {
-			if runtime.GOOS == "windows" {
-				path = "C:/users/ContainerAdministrator/.jfrog/security/certs/cert.pem"
-			} else {
-				path = "/root/.jfrog/security/certs/cert.pem"
-			}
-		} else {
-			path = args.PEMFilePath
+
+	defer writeStepOutputs(outputs)
+	var registered []string
+	defer func() { removeRegisteredServers(ctx, executor, args, registered) }()
+
+	for _, cmd := range jfCommands(cmdList) {
+		execArgs := cmd.Argv()
+		result, err := runCommandWithRetry(ctx, executor, args, execArgs, cmd.Dir)
+		outputs.Collect(args, execArgs, result, err)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("Creating pem file at %q\n", path)
-		// write pen contents to path
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// remove filename from path
-			dir := filepath.Dir(path)
-			pemFolderErr := os.MkdirAll(dir, 0700)
-			if pemFolderErr != nil {
-				return fmt.Errorf("error creating pem folder: %s", pemFolderErr)
-			}
-			// write pem contents
-			pemWriteErr := os.WriteFile(path, []byte(args.PEMFileContents), 0600)
-			if pemWriteErr != nil {
-				return fmt.Errorf("error writing pem file: %s", pemWriteErr)
-			}
-			fmt.Printf("Successfully created pem file at %q\n", path)
+		if serverId, ok := configAddServerId(cmd); ok {
+			registered = append(registered, serverId)
 		}
 	}
-	// Take in spec file or use source/target arguments
-	if args.Spec != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--spec=%s", args.Spec))
-		if args.SpecVars != "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--spec-vars='%s'", args.SpecVars))
-		}
-	} else {
-		filteredTargetProps := filterTargetProps(args.TargetProps)
-		if filteredTargetProps != "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--target-props='%s'", filteredTargetProps))
-		}
-		if args.Source == "" {
-			return fmt.Errorf("source file needs to be set")
-		}
-		if args.Target == "" {
-			return fmt.Errorf("target path needs to be set")
+
+	if publishCmdArgs != nil {
+		if err := publishBuildInfo(ctx, executor, args, outputs); err != nil {
+			return err
 		}
-		cmdArgs = append(cmdArgs, fmt.Sprintf("\"%s\"", args.Source), args.Target)
 	}
 
-	cmdStr := strings.Join(cmdArgs[:], " ")
+	return nil
+}
 
-	shell, shArg := getShell()
+// GetUploadCommandArgs returns the jf command uploading the source files.
+func GetUploadCommandArgs(args Args) ([][]string, error) {
+	var cmdList [][]string
 
-	cmd := exec.Command(shell, shArg, cmdStr)
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
+	if args.URL == "" {
+		return cmdList, fmt.Errorf("JFrog Artifactory URL must be set, or anonymous access is not permitted")
+	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	trace(cmd)
+	opts, err := NewUploadOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return cmdList, err
+	}
 
-	err := cmd.Run()
+	serverId := serverID(args, tmpServerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+	cmdArgs := append([]string{"rt", "u", "--server-id=" + serverId}, opts.Flags()...)
+
+	// Add --build-number and --build-name flags if provided
+	if args.BuildNumber != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-number=%s", args.BuildNumber))
+	}
+	if args.BuildName != "" {
+		cmdArgs = append(cmdArgs, "--build-name="+args.BuildName)
+	}
+
+	// Take in spec file or use source/target arguments
+	cmdArgs = append(cmdArgs, opts.Paths()...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, cmdArgs)
+	return cmdList, nil
+}
+
+func publishBuildInfo(ctx context.Context, executor Executor, args Args, outputs *StepOutputs) error {
+	publishCmdArgs, err := GetPublishBuildInfoCommandArgs(args)
 	if err != nil {
 		return err
 	}
 
-	// Call publishBuildInfo if PLUGIN_PUBLISH_BUILD_INFO is set to true
-	if args.PublishBuildInfo {
-		if err := publishBuildInfo(args); err != nil {
-			return err
+	for _, cmd := range withBuildInfoSteps(args, jfCommands([][]string{publishCmdArgs})) {
+		execArgs := cmd.Argv()
+		result, err := runCommandWithRetry(ctx, executor, args, execArgs, cmd.Dir)
+		if isBuildAddGit(cmd.Args) {
+			if err != nil {
+				logrus.Println("Unable to add git info to the build info: ", err)
+			}
+			continue
+		}
+		outputs.Collect(args, execArgs, result, err)
+		if err != nil {
+			return fmt.Errorf("error publishing build info: %s", err)
 		}
 	}
 
 	return nil
 }
 
-func publishBuildInfo(args Args) error {
-	if args.BuildName == "" || args.BuildNumber == "" {
-		return fmt.Errorf("both build name and build number need to be set when publishing build info")
+// writeStepOutputs writes the outputs collected during the run. Failing to
+// write them is logged but does not fail the step.
+func writeStepOutputs(outputs *StepOutputs) {
+	if err := outputs.Write(); err != nil {
+		logrus.Println("Error writing step outputs: ", err)
 	}
+}
 
-	sanitizedURL, err := sanitizeURL(args.URL)
-	if err != nil {
-		return err
+// GetPublishBuildInfoCommandArgs returns the jf command publishing the build
+// info collected by the upload, through the server registered by
+// GetPublishBuildInfoServerCommandArgs.
+func GetPublishBuildInfoCommandArgs(args Args) ([]string, error) {
+	if args.BuildName == "" || args.BuildNumber == "" {
+		return nil, fmt.Errorf("both build name and build number need to be set when publishing build info")
+	}
+	if args.AccessToken == "" && (args.Username == "" || args.Password == "") {
+		return nil, fmt.Errorf("either access token or username/password need to be set for publishing build info")
 	}
 
 	publishCmdArgs := []string{
-		getJfrogBin(),
 		"rt",
 		"build-publish",
-		"\"" + args.BuildName + "\"",
-		"\"" + args.BuildNumber + "\"",
-		fmt.Sprintf("--url=%s", sanitizedURL),
+		args.BuildName,
+		args.BuildNumber,
+		"--server-id=" + serverID(args, tmpServerId+"bpi"),
 	}
+	return publishCmdArgs, nil
+}
 
-	if args.AccessToken != "" {
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--access-token=%sPLUGIN_ACCESS_TOKEN", getEnvPrefix()))
-	} else if args.Username != "" && args.Password != "" {
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--user=%sPLUGIN_USERNAME", getEnvPrefix()))
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--password=%sPLUGIN_PASSWORD", getEnvPrefix()))
-	} else {
-		return fmt.Errorf("either access token or username/password need to be set for publishing build info")
+// GetPublishBuildInfoServerCommandArgs returns the jf command registering the
+// server build info is published to.
+func GetPublishBuildInfoServerCommandArgs(args Args) ([]string, error) {
+	sanitizedURL, err := sanitizeURL(args.URL)
+	if err != nil {
+		return nil, err
 	}
 
-	publishCmdStr := strings.Join(publishCmdArgs, " ")
-	shell, shArg := getShell()
-	publishCmd := exec.Command(shell, shArg, publishCmdStr)
-	publishCmd.Env = os.Environ()
-	publishCmd.Env = append(publishCmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
-	publishCmd.Stdout = os.Stdout
-	publishCmd.Stderr = os.Stderr
-	trace(publishCmd)
-
-	if err := publishCmd.Run(); err != nil {
-		return fmt.Errorf("error publishing build info: %s", err)
+	// the access token is preferred to username/password
+	username, password := args.Username, args.Password
+	if args.AccessToken != "" {
+		username, password = "", ""
 	}
+	return GetConfigAddConfigCommandArgs(serverID(args, tmpServerId+"bpi"),
+		username, password, sanitizedURL, args.AccessToken, "")
+}
 
-	return nil
+// PrintDryRunPlan writes the commands that would be executed, in order,
+// with secrets masked.
+func PrintDryRunPlan(w io.Writer, args Args, cmdList []Command) {
+	fmt.Fprintf(w, "Dry run, %d command(s) would be executed:\n", len(cmdList))
+	redactor := NewRedactor(args)
+	for _, cmd := range cmdList {
+		fmt.Fprintf(w, "+ %s\n", redactor.Redact(cmd.String()))
+	}
 }
 
 // Function to filter TargetProps based on criteria
@@ -318,23 +511,37 @@ func sanitizeURL(inputURL string) (string, error) {
 	return parsedURL.String(), nil
 }
 
-// setAuthParams appends authentication parameters to cmdArgs based on the provided credentials.
+// setAuthParams appends the authentication parameters of jf config add to
+// cmdArgs based on the provided credentials. The secret itself is read by jf
+// from standard input, see authSecret.
 func setAuthParams(cmdArgs []string, args Args) ([]string, error) {
-	// Set authentication params
-	envPrefix := getEnvPrefix()
 	if args.Username != "" && args.Password != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--user %sPLUGIN_USERNAME", envPrefix))
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--password %sPLUGIN_PASSWORD", envPrefix))
+		cmdArgs = append(cmdArgs, "--user="+args.Username, passwordStdinFlag)
 	} else if args.APIKey != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--apikey %sPLUGIN_API_KEY", envPrefix))
+		// jf recognizes an API key passed as the password
+		if args.Username != "" {
+			cmdArgs = append(cmdArgs, "--user="+args.Username)
+		}
+		cmdArgs = append(cmdArgs, passwordStdinFlag)
 	} else if args.AccessToken != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--access-token %sPLUGIN_ACCESS_TOKEN", envPrefix))
+		cmdArgs = append(cmdArgs, accessTokenStdinFlag)
 	} else {
 		return nil, fmt.Errorf("either username/password, api key or access token needs to be set")
 	}
 	return cmdArgs, nil
 }
 
+// authSecret returns the credential selected by setAuthParams.
+func authSecret(args Args) string {
+	switch {
+	case args.Username != "" && args.Password != "":
+		return args.Password
+	case args.APIKey != "":
+		return args.APIKey
+	}
+	return args.AccessToken
+}
+
 func getShell() (string, string) {
 	if runtime.GOOS == "windows" {
 		// First check for PowerShell Core (pwsh.exe) which is used in PowerShell Nanoserver
@@ -358,13 +565,6 @@ func getJfrogBin() string {
 	return "jf"
 }
 
-func getEnvPrefix() string {
-	if runtime.GOOS == "windows" {
-		return "$Env:"
-	}
-	return "$"
-}
-
 func parseBoolOrDefault(defaultValue bool, s string) (result bool) {
 	var err error
 	result, err = strconv.ParseBool(s)
@@ -377,8 +577,8 @@ func parseBoolOrDefault(defaultValue bool, s string) (result bool) {
 
 // trace writes each command to stdout with the command wrapped in an xml
 // tag so that it can be extracted and displayed in the logs.
-func trace(cmd *exec.Cmd) {
-	fmt.Fprintf(os.Stdout, "+ %s\n", strings.Join(cmd.Args, " "))
+func trace(redactor *Redactor, argv []string) {
+	fmt.Fprintf(os.Stdout, "+ %s\n", redactor.Redact(strings.Join(argv, " ")))
 }
 
 func setSecureConnectProxies() {
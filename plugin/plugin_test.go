@@ -5,7 +5,12 @@
 package plugin
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -34,21 +39,21 @@ func TestSetAuthParams(t *testing.T) {
 		{
 			cmdArgs: []string{"executable", "arg1", "arg2"},
 			args:    Args{Username: "john", Password: "password123", APIKey: "", AccessToken: ""},
-			output:  []string{"executable", "arg1", "arg2", "--user $PLUGIN_USERNAME", "--password $PLUGIN_PASSWORD"},
+			output:  []string{"executable", "arg1", "arg2", "--user=john", "--password-stdin"},
 			err:     nil,
 		},
 		// Test case 2
 		{
 			cmdArgs: []string{"./app", "--flag"},
 			args:    Args{Username: "", Password: "", APIKey: "secretkey", AccessToken: ""},
-			output:  []string{"./app", "--flag", "--apikey $PLUGIN_API_KEY"},
+			output:  []string{"./app", "--flag", "--password-stdin"},
 			err:     nil,
 		},
 		// Test case 3
 		{
 			cmdArgs: []string{"script.sh", "-option"},
 			args:    Args{Username: "", Password: "", APIKey: "", AccessToken: "token123"},
-			output:  []string{"script.sh", "-option", "--access-token $PLUGIN_ACCESS_TOKEN"},
+			output:  []string{"script.sh", "-option", "--access-token-stdin"},
 			err:     nil,
 		},
 		// Test case 4
@@ -62,7 +67,7 @@ func TestSetAuthParams(t *testing.T) {
 		{
 			cmdArgs: []string{"app", "-flag"},
 			args:    Args{Username: "user", Password: "", APIKey: "apikey123", AccessToken: ""},
-			output:  []string{"app", "-flag", "--apikey $PLUGIN_API_KEY"},
+			output:  []string{"app", "-flag", "--user=user", "--password-stdin"},
 			err:     nil,
 		},
 	}
@@ -141,3 +146,92 @@ func TestSanitizeURL(t *testing.T) {
 		}
 	}
 }
+
+func TestGetUploadCommandArgs(t *testing.T) {
+	args := Args{
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		Source:      "dist/app.jar",
+		Target:      "libs-release-local/app/",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		TargetProps: "key1=value1,key2=null",
+	}
+	cmdList, err := GetUploadCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
+		"rt u --server-id=tmpServerId --flat=false --build-number=v1.0 --build-name=t2 --target-props=key1=value1 " +
+			"dist/app.jar libs-release-local/app/",
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d", len(wantCmds), len(cmdList))
+	}
+	for i, want := range wantCmds {
+		if got := strings.Join(cmdList[i], " "); got != want {
+			t.Errorf("Expected: %s, Got: %s", want, got)
+		}
+	}
+}
+
+func TestPrintDryRunPlan(t *testing.T) {
+	args := Args{
+		BuildTool:   GradleCmd,
+		Command:     "publish",
+		Username:    "ab",
+		Password:    "s3cr3t",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		DeployerId:  RtDeployerId,
+		DryRun:      true,
+	}
+	cmdList, err := GetRtCommandsList(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	PrintDryRunPlan(&buf, args, cmdList)
+	out := buf.String()
+
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("Expected password to be masked, got:\n%s", out)
+	}
+	wantLines := []string{
+		"Dry run, 4 command(s) would be executed:",
+		"+ jf config add " + RtDeployerId,
+		"+ jf gradle publish -Pusername=ab --build-name=t2 --build-number=v1.0",
+		"+ jf rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected plan to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestExecDryRunDoesNotExecute(t *testing.T) {
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Source:           "dist/app.jar",
+		Target:           "libs-release-local/app/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		PEMFileContents:  "pem",
+		PEMFilePath:      filepath.Join(t.TempDir(), "certs", "cert.pem"),
+		DryRun:           true,
+	}
+	if err := Exec(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(args.PEMFilePath); !os.IsNotExist(err) {
+		t.Errorf("Expected no pem file to be written in dry run mode")
+	}
+}
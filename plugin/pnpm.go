@@ -0,0 +1,231 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	pnpmLockfile = "pnpm-lock.yaml"
+	npmrcEnv     = "NPM_CONFIG_USERCONFIG"
+)
+
+// preparePnpmRegistry points pnpm to the resolve repository with an npmrc
+// of the run holding the credentials of the step, as jf has no pnpm-config.
+func preparePnpmRegistry(args *Args) error {
+	if args.workDir == "" || normalizeRtName(args.BuildTool) != PnpmCmd {
+		return nil
+	}
+	opts, err := NewPnpmOptions(*args)
+	// invalid settings are reported by the pnpm commands
+	if err != nil || opts.RepoResolve == "" {
+		return nil
+	}
+	npmrc, err := pnpmNpmrc(*args, opts)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(args.workDir, "npmrc")
+	if err := os.WriteFile(path, []byte(npmrc), 0600); err != nil {
+		return fmt.Errorf("error writing npmrc: %s", err)
+	}
+	args.npmrcPath = path
+	return nil
+}
+
+// pnpmNpmrc returns the npmrc resolving packages from the resolve
+// repository with the credentials of the step.
+func pnpmNpmrc(args Args, opts NodeOptions) (string, error) {
+	baseURL, err := sanitizeURL(args.URL)
+	if err != nil {
+		return "", err
+	}
+	registry := baseURL + "api/npm/" + strings.Trim(opts.RepoResolve, "/") + "/"
+	authKey := "//" + registry[strings.Index(registry, "://")+3:] + ":"
+
+	var auth string
+	switch {
+	case args.AccessToken != "":
+		auth = authKey + "_authToken=" + args.AccessToken
+	case args.Username != "" && args.Password != "":
+		auth = authKey + "_auth=" + base64.StdEncoding.EncodeToString([]byte(args.Username+":"+args.Password))
+	case args.Username != "" && args.APIKey != "":
+		auth = authKey + "_auth=" + base64.StdEncoding.EncodeToString([]byte(args.Username+":"+args.APIKey))
+	default:
+		return "", errors.New("either access token or username/password need to be set for pnpm")
+	}
+	return "registry=" + registry + "\n" + auth + "\n", nil
+}
+
+// pnpmInstallArgs returns the arguments of pnpm install, installing exactly
+// the versions of the lockfile when there is one.
+func (o NodeOptions) pnpmInstallArgs() ([]string, error) {
+	installArgs := []string{"install"}
+	if _, err := os.Stat(pnpmLockfile); err == nil && o.Args == "" {
+		installArgs = append(installArgs, "--frozen-lockfile")
+	}
+	pnpmArgs, err := splitArgs(o.Args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PLUGIN_PNPM_ARGS: %w", err)
+	}
+	return append(installArgs, pnpmArgs...), nil
+}
+
+func GetPnpmBuildCommandArgs(args Args) ([]Command, error) {
+
+	var cmdList []Command
+
+	opts, err := NewPnpmOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	installArgs, err := opts.pnpmInstallArgs()
+	if err != nil {
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, Command{Args: jfrogConfigAddConfigCommandArgs})
+	cmdList = append(cmdList, Command{Program: PnpmCmd, Args: installArgs})
+
+	// jf does not run pnpm, the dependencies of the build info are added
+	// from the lockfile instead
+	if args.BuildName == "" || args.BuildNumber == "" {
+		return cmdList, nil
+	}
+	deps, err := pnpmLockfileDependencies(pnpmLockfile)
+	if errors.Is(err, os.ErrNotExist) {
+		logrus.Println("No ", pnpmLockfile, ", the build info has no dependencies")
+		return cmdList, nil
+	}
+	if err != nil {
+		return cmdList, err
+	}
+	if len(deps) == 0 {
+		return cmdList, nil
+	}
+
+	specPath, err := writeTempFile(args, getTimestampedFileName(), pnpmDependenciesSpec(opts.RepoResolve, deps))
+	if err != nil {
+		return cmdList, err
+	}
+	addDependenciesCommandArgs := []string{"rt", "build-add-dependencies", "--from-rt", "--spec=" + specPath,
+		"--server-id=" + serverId}
+	var depFlags cmdFlags
+	depFlags.addString("--module", args.Module)
+	addDependenciesCommandArgs = append(addDependenciesCommandArgs, depFlags...)
+	addDependenciesCommandArgs = append(addDependenciesCommandArgs, args.BuildName, args.BuildNumber)
+
+	cmdList = append(cmdList, Command{Args: addDependenciesCommandArgs})
+
+	return cmdList, nil
+}
+
+// nodeDependency is a package version of a lockfile.
+type nodeDependency struct {
+	Name    string
+	Version string
+}
+
+// pnpmLockfileDependencies returns the registry packages of a pnpm
+// lockfile, sorted by name and version. Packages of other sources, such as
+// git or local directories, are skipped.
+func pnpmLockfileDependencies(path string) ([]nodeDependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lockfile struct {
+		Packages map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lockfile); err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", path, err)
+	}
+
+	var deps []nodeDependency
+	for key := range lockfile.Packages {
+		if dep, ok := parsePnpmPackageKey(key); ok {
+			deps = append(deps, dep)
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+	return deps, nil
+}
+
+// parsePnpmPackageKey returns the package of a key of the packages of a
+// pnpm lockfile, such as /@babel/core@7.24.0 (lockfile v6),
+// react-dom@18.2.0(react@18.2.0) (v9) or /lodash/4.17.21 (v5).
+func parsePnpmPackageKey(key string) (nodeDependency, bool) {
+	key = strings.TrimPrefix(key, "/")
+	if i := strings.Index(key, "("); i > 0 {
+		// peer dependencies of the package
+		key = key[:i]
+	}
+
+	scope := ""
+	if strings.HasPrefix(key, "@") {
+		i := strings.Index(key, "/")
+		if i < 0 {
+			return nodeDependency{}, false
+		}
+		scope, key = key[:i+1], key[i+1:]
+	}
+	i := strings.IndexAny(key, "@/")
+	if i <= 0 {
+		return nodeDependency{}, false
+	}
+	dep := nodeDependency{Name: scope + key[:i], Version: key[i+1:]}
+	if j := strings.Index(dep.Version, "_"); j > 0 {
+		// peer dependencies of lockfile v5
+		dep.Version = dep.Version[:j]
+	}
+	if dep.Version == "" || dep.Version[0] < '0' || dep.Version[0] > '9' || strings.ContainsAny(dep.Version, "/:") {
+		return nodeDependency{}, false
+	}
+	return dep, true
+}
+
+// pnpmDependenciesSpec returns the file spec finding the tarballs of deps in
+// repo, where the scoped tarballs may be named with or without the scope.
+func pnpmDependenciesSpec(repo string, deps []nodeDependency) string {
+	type specFile struct {
+		Pattern string `json:"pattern"`
+	}
+	spec := struct {
+		Files []specFile `json:"files"`
+	}{}
+	repo = strings.Trim(repo, "/")
+	for _, dep := range deps {
+		baseName := dep.Name[strings.LastIndex(dep.Name, "/")+1:]
+		spec.Files = append(spec.Files, specFile{
+			Pattern: fmt.Sprintf("%s/%s/-/*%s-%s.tgz", repo, dep.Name, baseName, dep.Version),
+		})
+	}
+	content, _ := json.MarshalIndent(spec, "", "  ")
+	return string(content)
+}
@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPnpmLockfile = `lockfileVersion: '9.0'
+
+importers:
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@18.2.0)
+
+packages:
+  '@babel/core@7.24.0':
+    resolution: {integrity: sha512-abc}
+  react-dom@18.2.0(react@18.2.0):
+    resolution: {integrity: sha512-def}
+  react@18.2.0:
+    resolution: {integrity: sha512-ghi}
+  local-lib@file:../local-lib:
+    resolution: {directory: ../local-lib, type: directory}
+`
+
+func TestParsePnpmPackageKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want nodeDependency
+		ok   bool
+	}{
+		{"/@babel/core@7.24.0", nodeDependency{"@babel/core", "7.24.0"}, true},
+		{"react-dom@18.2.0(react@18.2.0)", nodeDependency{"react-dom", "18.2.0"}, true},
+		{"/lodash/4.17.21", nodeDependency{"lodash", "4.17.21"}, true},
+		{"/@types/node/20.1.0_typescript@5.0.0", nodeDependency{"@types/node", "20.1.0"}, true},
+		{"local-lib@file:../local-lib", nodeDependency{}, false},
+		{"github.com/acme/lib/0123abc", nodeDependency{}, false},
+	}
+	for _, tc := range tests {
+		got, ok := parsePnpmPackageKey(tc.key)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("parsePnpmPackageKey(%q) = %v, %v, expected %v, %v", tc.key, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestGetPnpmBuildCommandArgs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(pnpmLockfile, []byte(testPnpmLockfile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workDir := t.TempDir()
+
+	args := Args{
+		BuildTool:   "pnpm",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoResolve: "npm-virtual",
+		Module:      "web",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		workDir:     workDir,
+	}
+	cmdList, err := GetPnpmBuildCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cmdList) != 3 {
+		t.Fatalf("Expected 3 commands, got %d: %v", len(cmdList), cmdList)
+	}
+	if got, want := commandLine(cmdList[1]), "pnpm install --frozen-lockfile"; got != want {
+		t.Errorf("Expected: %s, Got: %s", want, got)
+	}
+
+	addDeps := commandLine(cmdList[2])
+	wantPrefix := "rt build-add-dependencies --from-rt --spec=" + workDir
+	wantSuffix := "--server-id=tmpServerId --module=web t2 v1.0"
+	if !strings.HasPrefix(addDeps, wantPrefix) || !strings.HasSuffix(addDeps, wantSuffix) {
+		t.Fatalf("Expected the dependencies to be added from a spec of the run, got %s", addDeps)
+	}
+	specPath := strings.TrimPrefix(cmdList[2].Args[3], "--spec=")
+	spec, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`"npm-virtual/@babel/core/-/*core-7.24.0.tgz"`,
+		`"npm-virtual/react-dom/-/*react-dom-18.2.0.tgz"`,
+		`"npm-virtual/react/-/*react-18.2.0.tgz"`,
+	} {
+		if !strings.Contains(string(spec), want) {
+			t.Errorf("Expected the spec to contain %s, got:\n%s", want, spec)
+		}
+	}
+	if strings.Contains(string(spec), "local-lib") {
+		t.Errorf("Expected local packages to be skipped, got:\n%s", spec)
+	}
+}
+
+func TestGetPnpmBuildCommandArgsWritesNoSpecInDryRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(pnpmLockfile, []byte(testPnpmLockfile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{
+		BuildTool:   "pnpm",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoResolve: "npm-virtual",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		DryRun:      true,
+	}
+	cmdList, err := GetPnpmBuildCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cmdList) != 3 {
+		t.Fatalf("Expected 3 commands, got %d: %v", len(cmdList), cmdList)
+	}
+	specPath := strings.TrimPrefix(cmdList[2].Args[3], "--spec=")
+	if _, err := os.Stat(specPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no spec to be written in a dry run, got %v", err)
+	}
+}
+
+func TestPreparePnpmRegistry(t *testing.T) {
+	args := Args{
+		BuildTool:   "pnpm",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         "https://acme.jfrog.io/artifactory/api/",
+		RepoResolve: "npm-virtual",
+		workDir:     t.TempDir(),
+	}
+	if err := preparePnpmRegistry(&args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.npmrcPath != filepath.Join(args.workDir, "npmrc") {
+		t.Fatalf("Expected an npmrc in the run directory, got %q", args.npmrcPath)
+	}
+	npmrc, err := os.ReadFile(args.npmrcPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "registry=https://acme.jfrog.io/artifactory/api/npm/npm-virtual/\n" +
+		"//acme.jfrog.io/artifactory/api/npm/npm-virtual/:_auth=YWI6Y2Q=\n"
+	if string(npmrc) != want {
+		t.Errorf("Expected npmrc:\n%s\nGot:\n%s", want, npmrc)
+	}
+
+	args = Args{BuildTool: "npm", RepoResolve: "npm-virtual", workDir: t.TempDir()}
+	if err := preparePnpmRegistry(&args); err != nil || args.npmrcPath != "" {
+		t.Errorf("Expected no npmrc for other build tools, got %q, %v", args.npmrcPath, err)
+	}
+}
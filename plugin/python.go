@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDistDir is the directory python build backends write wheels and
+// sdists to.
+const defaultDistDir = "dist"
+
+// PythonOptions are the settings of the pip, pipenv and poetry commands.
+type PythonOptions struct {
+	Args            string `split_words:"true"`
+	DistDir         string `split_words:"true"`
+	RepoResolve     string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	ServerIdResolve string `split_words:"true"`
+	Global          bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+	Threads         int    `split_words:"true"`
+}
+
+// NewPythonOptions returns the python options of args, overridden by the
+// PLUGIN_PYTHON_ environment variables.
+func NewPythonOptions(args Args) (PythonOptions, error) {
+	opts := PythonOptions{
+		Args:            args.PythonArgs,
+		DistDir:         args.DistDir,
+		RepoResolve:     args.RepoResolve,
+		RepoDeploy:      args.RepoDeploy,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		ServerIdResolve: args.ServerIdResolve,
+		Global:          args.Global,
+		DetailedSummary: args.DetailedSummary,
+		Threads:         args.Threads,
+	}
+	err := loadOptions(PythonOptionsPrefix, &opts)
+	opts.DistDir = valueOrDefault(opts.DistDir, defaultDistDir)
+	return opts, err
+}
+
+// Validate returns the problems of the python options.
+func (o PythonOptions) Validate() []error {
+	return notNegative("PLUGIN_THREADS", o.Threads)
+}
+
+// ValidateBuild returns the problems of the python options for installing
+// the dependencies.
+func (o PythonOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if o.RepoResolve == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_RESOLVE needs to be set"))
+	}
+	return problems
+}
+
+// ValidatePublish returns the problems of the python options for uploading
+// the distributions.
+func (o PythonOptions) ValidatePublish() []error {
+	problems := o.Validate()
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	return problems
+}
+
+// ConfigFlags returns the flags of jf pip-config, pipenv-config and
+// poetry-config.
+func (o PythonOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	return flags
+}
+
+// installArgs returns the arguments of the install command of tool. pip
+// installs the requirements file when there is one, and the project
+// otherwise.
+func (o PythonOptions) installArgs(tool string) ([]string, error) {
+	if o.Args != "" || tool != PipCmd {
+		pythonArgs, err := splitArgs(o.Args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PLUGIN_PYTHON_ARGS: %w", err)
+		}
+		return pythonArgs, nil
+	}
+	if _, err := os.Stat("requirements.txt"); err == nil {
+		return []string{"-r", "requirements.txt"}, nil
+	}
+	return []string{"."}, nil
+}
+
+// pythonBuildCommand returns the builder installing the dependencies of a
+// python project with tool.
+func pythonBuildCommand(tool string) RtCommandBuilder {
+	return jfBuilder(func(args Args) ([][]string, error) {
+		return GetPythonBuildCommandArgs(tool, args)
+	})
+}
+
+func GetPythonBuildCommandArgs(tool string, args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewPythonOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	pythonConfigCommandArgs := append([]string{tool + "-config"}, opts.ConfigFlags()...)
+
+	installArgs, err := opts.installArgs(tool)
+	if err != nil {
+		return cmdList, err
+	}
+	pythonInstallCommandArgs := append([]string{tool, "install"}, installArgs...)
+	pythonInstallCommandArgs = append(pythonInstallCommandArgs, moduleBuildFlags(args)...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, pythonConfigCommandArgs)
+	cmdList = append(cmdList, pythonInstallCommandArgs)
+
+	return cmdList, nil
+}
+
+func GetPythonPublishCommand(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewPythonOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidatePublish()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	// upload the wheels and sdists to the root of the deploy repository,
+	// where Artifactory indexes them
+	rtUploadCommandArgs := []string{"rt", "u", path.Join(opts.DistDir, "*"),
+		strings.TrimSuffix(opts.RepoDeploy, "/") + "/", "--server-id=" + serverId, "--flat=true"}
+	rtUploadCommandArgs = append(rtUploadCommandArgs, moduleBuildFlags(args)...)
+	var uploadFlags cmdFlags
+	uploadFlags.addInt("--threads", opts.Threads)
+	uploadFlags.addBool("--detailed-summary", opts.DetailedSummary)
+	rtUploadCommandArgs = append(rtUploadCommandArgs, uploadFlags...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, rtUploadCommandArgs)
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, buildDiscardCmd)
+	}
+
+	return cmdList, nil
+}
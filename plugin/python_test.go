@@ -0,0 +1,130 @@
+package plugin
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetPythonBuildCommandArgs(t *testing.T) {
+	tests := []struct {
+		buildTool    string
+		requirements bool
+		args         Args
+		want         []string
+	}{
+		{
+			buildTool:    "pip",
+			requirements: true,
+			args:         Args{RepoResolve: "pypi-virtual", ResolverId: RtRslvId},
+			want: []string{
+				"pip-config --repo-resolve=pypi-virtual --server-id-resolve=" + RtRslvId,
+				"pip install -r requirements.txt --build-name=t2 --build-number=v1.0",
+			},
+		},
+		{
+			buildTool: "pip",
+			args:      Args{RepoResolve: "pypi-virtual", Module: "api"},
+			want: []string{
+				"pip-config --repo-resolve=pypi-virtual --server-id-resolve=tmpServerId",
+				"pip install . --build-name=t2 --build-number=v1.0 --module=api",
+			},
+		},
+		{
+			buildTool: "pipenv",
+			args:      Args{RepoResolve: "pypi-virtual", PythonArgs: "--deploy"},
+			want: []string{
+				"pipenv-config --repo-resolve=pypi-virtual --server-id-resolve=tmpServerId",
+				"pipenv install --deploy --build-name=t2 --build-number=v1.0",
+			},
+		},
+		{
+			buildTool: "poetry",
+			args:      Args{RepoResolve: "pypi-virtual", Global: true},
+			want: []string{
+				"poetry-config --global=true --repo-resolve=pypi-virtual --server-id-resolve=tmpServerId",
+				"poetry install --build-name=t2 --build-number=v1.0",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.buildTool, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if tc.requirements {
+				if err := os.WriteFile("requirements.txt", []byte("requests\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			args := tc.args
+			args.BuildTool = tc.buildTool
+			args.AccessToken = RtAccessToken
+			args.URL = RtUrlTestStr
+			args.BuildName = RtBuildName
+			args.BuildNumber = RtBuildNumber
+
+			cmdList, err := GetRtCommandsList(args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(cmdList) != 3 {
+				t.Fatalf("Expected 3 commands, got %d: %v", len(cmdList), cmdList)
+			}
+			for i, want := range tc.want {
+				if got := commandLine(cmdList[i+1]); got != want {
+					t.Errorf("Expected: %s, Got: %s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPythonPublishCommand(t *testing.T) {
+	args := Args{
+		BuildTool:   "poetry",
+		Command:     "publish",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoDeploy:  "pypi-local/",
+		DeployerId:  RtDeployerId,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Threads:     2,
+	}
+	cmdList, err := GetPythonPublishCommand(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+		"rt u dist/* pypi-local/ --server-id=" + RtDeployerId + " --flat=true --build-name=t2 --build-number=v1.0 --threads=2",
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := strings.Join(cmd, " "); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestPythonOptionsValidate(t *testing.T) {
+	t.Setenv("PLUGIN_PYTHON_DIST_DIR", "build/dist")
+
+	opts, err := NewPythonOptions(Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.DistDir != "build/dist" {
+		t.Errorf("Expected the dist dir from the environment, got %q", opts.DistDir)
+	}
+	if problems := opts.ValidatePublish(); len(problems) != 1 || problems[0].Error() != "PLUGIN_REPO_DEPLOY needs to be set" {
+		t.Errorf("Expected a missing deploy repo, got %v", problems)
+	}
+	if problems := opts.ValidateBuild(); len(problems) != 1 || problems[0].Error() != "PLUGIN_REPO_RESOLVE needs to be set" {
+		t.Errorf("Expected a missing resolve repo, got %v", problems)
+	}
+}
@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redactedMask replaces secret values in the plugin output.
+const redactedMask = "****"
+
+// minSecretLength is the length of the shortest value redacted. Shorter
+// values would mask common words and numbers all over the output.
+const minSecretLength = 4
+
+// Redactor replaces the values of the secret fields of Args, the fields
+// tagged with secret:"true", in log lines, traces and error messages.
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor returns a Redactor for the secrets set in args.
+func NewRedactor(args Args) *Redactor {
+	secrets := basicAuthSecrets(args)
+	v := reflect.ValueOf(args)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("secret") != "true" {
+			continue
+		}
+		value, ok := v.Field(i).Interface().(string)
+		if !ok || len(strings.TrimSpace(value)) < minSecretLength {
+			continue
+		}
+		secrets = append(secrets, value)
+		// multi-line secrets such as PEM contents may be printed line by line
+		for _, line := range strings.Split(value, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) >= minSecretLength && line != value && !strings.HasPrefix(line, "-----") {
+				secrets = append(secrets, line)
+			}
+		}
+	}
+
+	// replace the longest secrets first so that a secret containing another
+	// one is masked as a whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return &Redactor{secrets: secrets}
+}
+
+// basicAuthSecrets returns the base64 encoded username and password or API
+// key of args, as written to the _auth entry of the npmrc of pnpm.
+func basicAuthSecrets(args Args) []string {
+	var secrets []string
+	for _, secret := range []string{args.Password, args.APIKey} {
+		if args.Username != "" && len(strings.TrimSpace(secret)) >= minSecretLength {
+			secrets = append(secrets, base64.StdEncoding.EncodeToString([]byte(args.Username+":"+secret)))
+		}
+	}
+	return secrets
+}
+
+// Redact returns s with every secret value replaced by the mask.
+func (r *Redactor) Redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedMask)
+	}
+	return s
+}
+
+// RedactError returns err with the secrets removed from its message.
+func (r *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := r.Redact(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// Writer returns a writer redacting every write before passing it to w. The
+// end of a write that may be the start of a secret is held back until the
+// next write, so that secrets split across writes are redacted too. Flush
+// writes the output held back.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	if len(r.secrets) == 0 {
+		return w
+	}
+	return &redactWriter{w: w, r: r}
+}
+
+// installLogRedaction redacts the output of the standard logger until the
+// returned function is called.
+func installLogRedaction(r *Redactor) func() {
+	logger := logrus.StandardLogger()
+	out := logger.Out
+	w := r.Writer(out)
+	logger.SetOutput(w)
+	return func() {
+		flushOutput(w)
+		logger.SetOutput(out)
+	}
+}
+
+// flushOutput writes the output held back by w, when w holds output back.
+func flushOutput(w io.Writer) {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+}
+
+type redactWriter struct {
+	w       io.Writer
+	r       *Redactor
+	pending []byte
+}
+
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	rw.pending = append(rw.pending, p...)
+	if err := rw.emit(rw.r.safePrefixLen(rw.pending)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush redacts and writes the output held back.
+func (rw *redactWriter) Flush() error {
+	return rw.emit(len(rw.pending))
+}
+
+// emit redacts and writes the first n bytes of the pending output.
+func (rw *redactWriter) emit(n int) error {
+	if n == 0 {
+		return nil
+	}
+	out := rw.r.Redact(string(rw.pending[:n]))
+	rw.pending = append(rw.pending[:0], rw.pending[n:]...)
+	_, err := io.WriteString(rw.w, out)
+	return err
+}
+
+// safePrefixLen returns the length of the start of s that can be redacted
+// and written without splitting a secret: s is cut before its longest
+// suffix starting a secret, and before any secret crossing the cut.
+func (r *Redactor) safePrefixLen(s []byte) int {
+	cut := len(s)
+	for _, secret := range r.secrets {
+		for n := min(len(secret)-1, len(s)); n > 0 && len(s)-n < cut; n-- {
+			if bytes.HasSuffix(s, []byte(secret[:n])) {
+				cut = len(s) - n
+				break
+			}
+		}
+	}
+	for moved := true; moved; {
+		moved = false
+		for _, secret := range r.secrets {
+			for start := 0; start < cut; {
+				i := bytes.Index(s[start:], []byte(secret))
+				if i < 0 {
+					break
+				}
+				i += start
+				if i < cut && i+len(secret) > cut {
+					cut, moved = i, true
+					break
+				}
+				start = i + 1
+			}
+		}
+	}
+	return cut
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactor(t *testing.T) {
+	args := Args{
+		Username:        "ab",
+		Password:        "p4ss",
+		AccessToken:     "t0k3n",
+		APIKey:          "k3yk3y",
+		PEMFileContents: "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIU\n-----END CERTIFICATE-----",
+	}
+	redactor := NewRedactor(args)
+
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{"--user=ab --password=p4ss", "--user=ab --password=****"},
+		{"--access-token=t0k3n --apikey=k3yk3y", "--access-token=**** --apikey=****"},
+		{"cert line MIIBszCCAVmgAwIBAgIU", "cert line ****"},
+		{":_auth=YWI6cDRzcw==", ":_auth=****"},
+		{":_auth=YWI6azN5azN5", ":_auth=****"},
+		{args.PEMFileContents, "****"},
+		{"nothing secret here", "nothing secret here"},
+	}
+	for _, tc := range tests {
+		if got := redactor.Redact(tc.input); got != tc.output {
+			t.Errorf("Redact(%q): Expected: %q, Got: %q", tc.input, tc.output, got)
+		}
+	}
+
+	err := redactor.RedactError(errors.New("login failed for p4ss"))
+	if err.Error() != "login failed for ****" {
+		t.Errorf("Expected redacted error, Got: %v", err)
+	}
+}
+
+func TestRedactorSkipsShortSecrets(t *testing.T) {
+	redactor := NewRedactor(Args{Password: "abc", AccessToken: "t0k3n"})
+	if got := redactor.Redact("abc t0k3n"); got != "abc ****" {
+		t.Errorf("Expected only secrets of at least %d characters to be redacted, got %q", minSecretLength, got)
+	}
+}
+
+func TestRedactWriterSecretSplitAcrossWrites(t *testing.T) {
+	redactor := NewRedactor(Args{Password: "p4ssw0rd", AccessToken: "w0rdt0k3n"})
+	tests := [][]string{
+		{"login with p4s", "sw0rd done\n"},
+		{"p", "4", "ssw0rd"},
+		{"token w0rd", "t0k3n and p4ssw0rd", "\n"},
+		{"partial p4ss", " only\n"},
+		// the start of the token overlaps the end of the password
+		{"log p4ssw0rd", "t0k3n\n"},
+	}
+	for _, writes := range tests {
+		var buf bytes.Buffer
+		w := redactor.Writer(&buf)
+		for _, p := range writes {
+			if n, err := w.Write([]byte(p)); n != len(p) || err != nil {
+				t.Fatalf("Unexpected write result %d, %v", n, err)
+			}
+		}
+		flushOutput(w)
+
+		want := redactor.Redact(strings.Join(writes, ""))
+		if buf.String() != want {
+			t.Errorf("Writes %q: Expected: %q, Got: %q", writes, want, buf.String())
+		}
+	}
+}
+
+func TestExecRedactsLogsAndErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.StandardLogger()
+	out := logger.Out
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(out)
+
+	args := Args{
+		Username:    "ab",
+		Password:    "p4ss",
+		URL:         RtUrlTestStr,
+		Source:      "dist/app.jar",
+		Target:      "libs-release-local/app/",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	var stdin string
+	executor := requestFunc(func(ctx context.Context, req ExecRequest) (ExecResult, error) {
+		stdin = req.Stdin
+		logrus.Println("uploading with p4ss")
+		return ExecResult{ExitCode: 1}, errors.New("authentication failed for p4ss")
+	})
+	err := ExecWithExecutor(context.Background(), args, executor)
+	if err == nil || err.Error() != "authentication failed for ****" {
+		t.Errorf("Expected redacted error, Got: %v", err)
+	}
+
+	// the executor receives the password but the plugin never prints it
+	if stdin != "p4ss" {
+		t.Errorf("Expected password to be passed to jf, got %q", stdin)
+	}
+	if strings.Contains(buf.String(), "p4ss") {
+		t.Errorf("Expected logs to be redacted, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,185 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRetryBackoff    = 2 * time.Second
+	defaultRetryMaxBackoff = time.Minute
+)
+
+// defaultRetryPatterns match the output of jf for transient server and
+// network errors. Status codes only match in an HTTP response, not in
+// versions or sizes printed by the build.
+var defaultRetryPatterns = []string{
+	`(?i)(status(?: code)?:?|server response:) 50[234]\b`,
+	`(?i)bad gateway`,
+	`(?i)service unavailable`,
+	`(?i)gateway time-?out`,
+	`(?i)connection (reset|refused)`,
+	`(?i)i/o timeout`,
+	`(?i)tls handshake timeout`,
+}
+
+// RetryPolicy decides whether a failed command is run again and how long to
+// wait before the next attempt.
+type RetryPolicy struct {
+	// Attempts is the total number of runs of a command, at least 1.
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction of it.
+	Jitter float64
+	// ExitCodes are retried whatever the command printed.
+	ExitCodes []int
+	// Patterns are matched against the output of the failed command.
+	Patterns []*regexp.Regexp
+	// NonIdempotent enables retrying commands that may have had side
+	// effects before failing, such as mvn deploy or a build promotion move.
+	NonIdempotent bool
+}
+
+// NewRetryPolicy returns the retry policy configured in args.
+func NewRetryPolicy(args Args) (*RetryPolicy, error) {
+	policy := &RetryPolicy{
+		Attempts:      args.CommandRetries + 1,
+		Backoff:       args.RetryBackoff,
+		MaxBackoff:    args.RetryMaxBackoff,
+		Jitter:        args.RetryJitter,
+		ExitCodes:     args.RetryExitCodes,
+		NonIdempotent: args.RetryNonIdempotent,
+	}
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultRetryBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return nil, fmt.Errorf("retry jitter must be between 0 and 1, got %v", policy.Jitter)
+	}
+
+	patterns := args.RetryPatterns
+	if len(patterns) == 0 {
+		patterns = defaultRetryPatterns
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry pattern %q: %s", pattern, err)
+		}
+		policy.Patterns = append(policy.Patterns, re)
+	}
+	return policy, nil
+}
+
+// Retryable reports whether the failed command may be run again.
+func (p *RetryPolicy) Retryable(cmdArgs []string, result ExecResult) bool {
+	if !p.NonIdempotent && !isIdempotentCommand(cmdArgs) {
+		return false
+	}
+	for _, code := range p.ExitCodes {
+		if result.ExitCode == code {
+			return true
+		}
+	}
+	output := result.Stderr + "\n" + result.Stdout
+	for _, re := range p.Patterns {
+		if re.MatchString(output) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the wait before the given retry, starting at 1, doubling
+// the backoff on each retry.
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// runCommandWithRetry runs the command in dir, running it again as allowed by
+// the retry policy of args.
+func runCommandWithRetry(ctx context.Context, executor Executor, args Args, cmdArgs []string, dir string) (ExecResult, error) {
+	policy, err := NewRetryPolicy(args)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := runCommand(ctx, executor, args, cmdArgs, dir)
+		if err == nil || attempt >= policy.Attempts || ctx.Err() != nil || !policy.Retryable(cmdArgs, result) {
+			return result, err
+		}
+
+		delay := policy.Delay(attempt)
+		logrus.Printf("Command failed with %s, retrying in %s (attempt %d of %d)\n",
+			err, delay.Round(time.Millisecond), attempt+1, policy.Attempts)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return result, err
+		}
+	}
+}
+
+// isIdempotentCommand reports whether running the command again after a
+// failure cannot duplicate or lose data. Commands not known to be safe to run
+// again, such as jf config add or programs other than jf, are not.
+func isIdempotentCommand(cmdArgs []string) bool {
+	if len(cmdArgs) == 0 || cmdArgs[0] != getJfrogBin() {
+		return false
+	}
+	switch subCmd := jfSubcommand(cmdArgs); subCmd {
+	case "mvn":
+		return !containsArg(cmdArgs, "deploy")
+	case "gradle":
+		return !containsArg(cmdArgs, "publish") && !containsArg(cmdArgs, "artifactoryPublish")
+	case "npm":
+		return !containsArg(cmdArgs, "publish")
+	case "rt build-promote", "rt bpr":
+		// a move removes the artifacts from the source repository
+		return containsArg(cmdArgs, "--copy=true")
+	case "rt build-discard", "rt bdi":
+		return !containsArg(cmdArgs, "--delete-artifacts=true")
+	case "rt u", "rt upload", "rt dl", "rt download", "rt build-publish", "rt bp",
+		"rt build-add-dependencies", "rt bad", "rt build-add-git", "rt bag",
+		"rt build-collect-env", "rt bce", "rt build-clean", "rt bc", "rt ping",
+		"build-scan", "bs", "go", "yarn", "pip", "pipenv", "poetry", "dotnet", "nuget",
+		"docker", "terraform":
+		return true
+	default:
+		// the -config commands rewrite the project configuration
+		return strings.HasSuffix(subCmd, "-config")
+	}
+}
+
+func containsArg(cmdArgs []string, arg string) bool {
+	for _, a := range cmdArgs {
+		if strings.EqualFold(a, arg) {
+			return true
+		}
+	}
+	return false
+}
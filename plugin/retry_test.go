@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommandWithRetryRetriesTransientErrors(t *testing.T) {
+	args := Args{CommandRetries: 2, RetryBackoff: time.Millisecond}
+	var ran int
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		ran++
+		if ran < 3 {
+			return ExecResult{ExitCode: 1, Stderr: "server response: 503 Service Unavailable"}, errors.New("exit status 1")
+		}
+		return ExecResult{}, nil
+	})
+
+	if _, err := runCommandWithRetry(context.Background(), executor, args, []string{"jf", "rt", "u", "a", "b"}, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ran != 3 {
+		t.Errorf("Expected 3 attempts, got %d", ran)
+	}
+}
+
+func TestRunCommandWithRetryStopsAfterAttempts(t *testing.T) {
+	args := Args{CommandRetries: 1, RetryBackoff: time.Millisecond}
+	var ran int
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		ran++
+		return ExecResult{ExitCode: 1, Stderr: "connection reset by peer"}, errors.New("exit status 1")
+	})
+
+	if _, err := runCommandWithRetry(context.Background(), executor, args, []string{"jf", "rt", "u", "a", "b"}, ""); err == nil {
+		t.Fatalf("Expected error after the last attempt")
+	}
+	if ran != 2 {
+		t.Errorf("Expected 2 attempts, got %d", ran)
+	}
+}
+
+func TestRunCommandWithRetrySkipsNonRetryable(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    Args
+		cmdArgs []string
+		result  ExecResult
+		want    int
+	}{
+		{
+			name:    "permanent error",
+			args:    Args{CommandRetries: 3, RetryBackoff: time.Millisecond},
+			cmdArgs: []string{"jf", "rt", "u", "a", "b"},
+			result:  ExecResult{ExitCode: 1, Stderr: "401 Unauthorized"},
+			want:    1,
+		},
+		{
+			name:    "maven deploy",
+			args:    Args{CommandRetries: 3, RetryBackoff: time.Millisecond},
+			cmdArgs: []string{"jf", "mvn", "clean", "deploy"},
+			result:  ExecResult{ExitCode: 1, Stderr: "502 Bad Gateway"},
+			want:    1,
+		},
+		{
+			name:    "maven deploy opted in",
+			args:    Args{CommandRetries: 3, RetryBackoff: time.Millisecond, RetryNonIdempotent: true},
+			cmdArgs: []string{"jf", "mvn", "clean", "deploy"},
+			result:  ExecResult{ExitCode: 1, Stderr: "502 Bad Gateway"},
+			want:    4,
+		},
+		{
+			name:    "retryable exit code",
+			args:    Args{CommandRetries: 1, RetryBackoff: time.Millisecond, RetryExitCodes: []int{2}},
+			cmdArgs: []string{"jf", "rt", "build-publish", "t2", "v1.0"},
+			result:  ExecResult{ExitCode: 2},
+			want:    2,
+		},
+		{
+			name:    "custom pattern",
+			args:    Args{CommandRetries: 1, RetryBackoff: time.Millisecond, RetryPatterns: []string{"(?i)rate limit"}},
+			cmdArgs: []string{"jf", "rt", "u", "a", "b"},
+			result:  ExecResult{ExitCode: 1, Stderr: "502 Bad Gateway"},
+			want:    1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var ran int
+			executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+				ran++
+				return tc.result, errors.New("exit status 1")
+			})
+			if _, err := runCommandWithRetry(context.Background(), executor, tc.args, tc.cmdArgs, ""); err == nil {
+				t.Fatalf("Expected error")
+			}
+			if ran != tc.want {
+				t.Errorf("Expected %d attempts, got %d", tc.want, ran)
+			}
+		})
+	}
+}
+
+func TestRunCommandWithRetryCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	args := Args{CommandRetries: 3, RetryBackoff: time.Hour}
+	var ran int
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		ran++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return ExecResult{ExitCode: 1, Stderr: "504 Gateway Timeout"}, errors.New("exit status 1")
+	})
+
+	start := time.Now()
+	if _, err := runCommandWithRetry(ctx, executor, args, []string{"jf", "rt", "u", "a", "b"}, ""); err == nil {
+		t.Fatalf("Expected error")
+	}
+	if ran != 1 {
+		t.Errorf("Expected 1 attempt, got %d", ran)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the backoff to stop on cancellation, took %s", elapsed)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy, err := NewRetryPolicy(Args{RetryBackoff: time.Second, RetryMaxBackoff: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := policy.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d): expected %s, got %s", i+1, w, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Delay with jitter out of range: %s", got)
+		}
+	}
+}
+
+func TestNewRetryPolicyInvalid(t *testing.T) {
+	if _, err := NewRetryPolicy(Args{RetryPatterns: []string{"("}}); err == nil || !strings.Contains(err.Error(), "invalid retry pattern") {
+		t.Errorf("Expected invalid pattern error, got %v", err)
+	}
+	if _, err := NewRetryPolicy(Args{RetryJitter: 2}); err == nil {
+		t.Errorf("Expected invalid jitter error")
+	}
+}
+
+func TestIsIdempotentCommand(t *testing.T) {
+	tests := []struct {
+		cmdArgs []string
+		want    bool
+	}{
+		{[]string{"jf", "rt", "u", "a", "b"}, true},
+		{[]string{"jf", "mvn", "clean", "install"}, true},
+		{[]string{"jf", "mvn", "clean", "deploy"}, false},
+		{[]string{"jf", "gradle", "clean", "artifactoryPublish"}, false},
+		{[]string{"jf", "npm", "ci", "--build-name=t2"}, true},
+		{[]string{"jf", "npm", "publish", "--build-name=t2"}, false},
+		{[]string{"jf", "go-publish", "v1.2.3"}, false},
+		{[]string{"jf", "rt", "build-promote", "--copy=true", "t2", "v1.0", "repo"}, true},
+		{[]string{"jf", "rt", "build-promote", "t2", "v1.0", "repo"}, false},
+		{[]string{"jf", "rt", "build-discard", "--delete-artifacts=true", "t2"}, false},
+		{[]string{"jf", "rt", "build-discard", "--max-builds=5", "t2"}, true},
+		{[]string{"jf", "mvn-config", "--repo-resolve-releases=libs-release"}, true},
+		{[]string{"jf", "config", "add", "tmpServerId", "--interactive=false"}, false},
+		{[]string{"jf", "rt", "curl", "-XPOST", "/api/helm/helm-local/reindex"}, false},
+		{[]string{"helm", "package", "chart"}, false},
+		{[]string{"pnpm", "install"}, false},
+	}
+	for _, tc := range tests {
+		if got := isIdempotentCommand(tc.cmdArgs); got != tc.want {
+			t.Errorf("isIdempotentCommand(%q): expected %v, got %v", tc.cmdArgs, tc.want, got)
+		}
+	}
+}
+
+func TestDefaultRetryPatterns(t *testing.T) {
+	policy, err := NewRetryPolicy(Args{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"server response: 502 Bad Gateway", true},
+		{"unexpected status code: 504", true},
+		{"Status: 503", true},
+		{"[Error] Downloaded 503 files, installed lodash 4.17.502", false},
+		{"401 Unauthorized", false},
+	}
+	for _, tc := range tests {
+		result := ExecResult{ExitCode: 1, Stderr: tc.output}
+		if got := policy.Retryable([]string{"jf", "rt", "u", "a", "b"}, result); got != tc.want {
+			t.Errorf("Retryable(%q): expected %v, got %v", tc.output, tc.want, got)
+		}
+	}
+}
@@ -1,32 +1,26 @@
 package plugin
 
 import (
+	"errors"
+
 	"github.com/sirupsen/logrus"
 )
 
-var BuildDiscardCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
-	{"--async=", "PLUGIN_ASYNC", false, false},
-	{"--delete-artifacts=", "PLUGIN_DELETE_ARTIFACTS", false, false},
-	{"--exclude-builds=", "PLUGIN_EXCLUDE_BUILDS", false, false},
-	{"--max-builds=", "PLUGIN_MAX_BUILDS", false, false},
-	{"--max-days=", "PLUGIN_MAX_DAYS", false, false},
-}
-
+// GetBuildDiscardCommandArgs returns the jf commands registering a
+// temporary server and discarding old builds with it.
 func GetBuildDiscardCommandArgs(args Args) ([][]string, error) {
-	bdiServerId := tmpServerId + "bdi"
+	serverId := serverID(args, tmpServerId)
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(bdiServerId,
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
 		return cmdList, err
 	}
 
-	buildDiscardCmd, err := GetBuildDiscardCommand(args)
-
+	buildDiscardCmd, err := buildDiscardCommand(args, serverId)
 	if err != nil {
-		logrus.Println("Error in GetBuildDiscardCommand ", err)
 		return cmdList, err
 	}
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
@@ -34,13 +28,29 @@ func GetBuildDiscardCommandArgs(args Args) ([][]string, error) {
 	return cmdList, nil
 }
 
+// buildDiscardCommand returns the jf command discarding old builds with
+// serverId, which publishing commands have registered already.
+func buildDiscardCommand(args Args, serverId string) ([]string, error) {
+	buildDiscardCmd, err := GetBuildDiscardCommand(args)
+	if err != nil {
+		logrus.Println("Error in GetBuildDiscardCommand ", err)
+		return nil, err
+	}
+	// the build name is the last argument
+	last := len(buildDiscardCmd) - 1
+	return append(buildDiscardCmd[:last:last], "--server-id="+serverId, buildDiscardCmd[last]), nil
+}
+
 func GetBuildDiscardCommand(args Args) ([]string, error) {
 	buildDiscardCommandArgs := []string{"rt", "build-discard"}
-	err := PopulateArgs(&buildDiscardCommandArgs, &args, BuildDiscardCmdJsonTagToExeFlagMapStringItemList)
+	opts, err := NewDiscardOptions(args)
 	if err != nil {
-		logrus.Println("Error in PopulateArgs ", err)
 		return buildDiscardCommandArgs, err
 	}
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return buildDiscardCommandArgs, err
+	}
+	buildDiscardCommandArgs = append(buildDiscardCommandArgs, opts.Flags()...)
 	buildDiscardCommandArgs = append(buildDiscardCommandArgs, args.BuildName)
 	return buildDiscardCommandArgs, nil
 }
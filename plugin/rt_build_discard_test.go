@@ -29,8 +29,8 @@ func TestBuildDiscard(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerIdbdi --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
-		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 t2",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
+		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 --server-id=tmpServerId t2",
 	}
 
 	if len(gotCmds) != len(wantCmds) {
@@ -69,12 +69,11 @@ func TestGradleBuildDiscard(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
-		"gradle-config --server-id-deploy= --server-id-resolve=",
-		"gradle publish -Pusername=ab0 -Ppassword=cd --build-name=t2 --build-number=v1.0",
-		"rt build-publish t2 v1.0 --server-id=",
-		"config add tmpServerIdbdi --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
-		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 t2",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab0 --password-stdin --interactive=false",
+		"gradle-config --server-id-deploy=tmpServerId --server-id-resolve=tmpServerId",
+		"gradle publish -Pusername=ab0 --build-name=t2 --build-number=v1.0",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
+		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 --server-id=tmpServerId t2",
 	}
 
 	if len(gotCmds) != len(wantCmds) {
@@ -113,12 +112,11 @@ func TestMvnBuildDiscard(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab0 --password-stdin --interactive=false",
 		"mvn-config",
 		"mvn deploy --build-name=t2 --build-number=v1.0",
-		"rt build-publish t2 v1.0 --server-id=",
-		"config add tmpServerIdbdi --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
-		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 t2",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
+		"rt build-discard --delete-artifacts=true --max-builds=5 --max-days=7 --server-id=tmpServerId t2",
 	}
 
 	if len(gotCmds) != len(wantCmds) {
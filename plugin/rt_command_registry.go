@@ -0,0 +1,492 @@
+package plugin
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RtCommandBuilder returns the ordered list of commands for a plugin command.
+type RtCommandBuilder func(args Args) ([]Command, error)
+
+// RtCommand describes a plugin command that HandleRtCommands can dispatch.
+type RtCommand struct {
+	// Name is the value of PLUGIN_COMMAND selecting this command.
+	Name string
+	// BuildTool is the PLUGIN_BUILD_TOOL the command belongs to, empty for
+	// commands that do not depend on a build tool.
+	BuildTool string
+	// Aliases are alternative names accepted for Name.
+	Aliases []string
+	// RequiredFields lists the envconfig tags of Args that must be set.
+	RequiredFields []string
+	// ExclusiveFields lists groups of envconfig tags of which at most one
+	// may be set.
+	ExclusiveFields [][]string
+	// FileFields lists the envconfig tags of settings naming files that
+	// must exist.
+	FileFields []string
+	// NoAuth is set for commands that do not contact Artifactory.
+	NoAuth bool
+	// PublishesBuildInfo is set for commands that publish build info, which
+	// are the only ones given a default build name and number.
+	PublishesBuildInfo bool
+	// Validate checks the settings of the command beyond the fields above.
+	Validate func(args Args) []error
+	// Help is a one line description of the command.
+	Help string
+	// Builder renders the commands.
+	Builder RtCommandBuilder
+}
+
+// rtBuildToolAliases maps alternative build tool names to the canonical name.
+var rtBuildToolAliases = map[string]string{
+	"maven": MvnCmd,
+}
+
+// RtCommandRegistry lists every command known to the plugin. New commands
+// are added here rather than in GetRtCommandsList.
+var RtCommandRegistry = []RtCommand{
+	{
+		Name:           "build",
+		BuildTool:      MvnCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		FileFields:     []string{"PLUGIN_POM_FILE"},
+		Validate:       validateOptions(NewMavenOptions, MavenOptions.ValidateBuild),
+		Help:           "run maven goals resolving dependencies from Artifactory",
+		Builder:        jfBuilder(GetMavenBuildCommandArgs),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          MvnCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		FileFields:         []string{"PLUGIN_POM_FILE"},
+		Validate:           validateOptions(NewMavenOptions, MavenOptions.Validate),
+		PublishesBuildInfo: true,
+		Help:               "deploy maven artifacts and publish build info",
+		Builder:            jfBuilder(GetMavenPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      GradleCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		FileFields:     []string{"PLUGIN_BUILD_FILE"},
+		Validate:       validateOptions(NewGradleOptions, GradleOptions.ValidateBuild),
+		Help:           "run gradle tasks resolving dependencies from Artifactory",
+		Builder:        jfBuilder(GetGradleCommandArgs),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          GradleCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		FileFields:         []string{"PLUGIN_BUILD_FILE"},
+		Validate:           validateOptions(NewGradleOptions, GradleOptions.Validate),
+		PublishesBuildInfo: true,
+		Help:               "publish gradle artifacts and build info",
+		Builder:            jfBuilder(GetGradlePublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      NpmCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewNpmOptions, NpmOptions.ValidateBuild),
+		Help:           "install npm dependencies resolving them from Artifactory",
+		Builder:        jfBuilder(GetNpmBuildCommandArgs),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          NpmCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewNpmOptions, NpmOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "publish an npm package and build info",
+		Builder:            jfBuilder(GetNpmPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      YarnCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewYarnOptions, NodeOptions.ValidateBuild),
+		Help:           "install yarn dependencies resolving them from Artifactory",
+		Builder:        jfBuilder(GetYarnBuildCommandArgs),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          YarnCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewYarnOptions, NodeOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "pack and upload a yarn package and publish build info",
+		Builder:            nodePublishCommand(YarnCmd),
+	},
+	{
+		Name:           "build",
+		BuildTool:      PnpmCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewPnpmOptions, NodeOptions.ValidateBuild),
+		Help:           "install pnpm dependencies resolving them from Artifactory",
+		Builder:        GetPnpmBuildCommandArgs,
+	},
+	{
+		Name:               Publish,
+		BuildTool:          PnpmCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewPnpmOptions, NodeOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "pack and upload a pnpm package and publish build info",
+		Builder:            nodePublishCommand(PnpmCmd),
+	},
+	{
+		Name:           "build",
+		BuildTool:      GoCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewGoOptions, GoOptions.ValidateBuild),
+		Help:           "build a go module resolving dependencies from Artifactory",
+		Builder:        jfBuilder(GetGoBuildCommandArgs),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          GoCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewGoOptions, GoOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "publish a go module version and build info",
+		Builder:            jfBuilder(GetGoPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      PipCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewPythonOptions, PythonOptions.ValidateBuild),
+		Help:           "install python dependencies with pip resolving them from Artifactory",
+		Builder:        pythonBuildCommand(PipCmd),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          PipCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewPythonOptions, PythonOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "upload python distributions and publish build info",
+		Builder:            jfBuilder(GetPythonPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      PipenvCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewPythonOptions, PythonOptions.ValidateBuild),
+		Help:           "install python dependencies with pipenv resolving them from Artifactory",
+		Builder:        pythonBuildCommand(PipenvCmd),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          PipenvCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewPythonOptions, PythonOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "upload python distributions and publish build info",
+		Builder:            jfBuilder(GetPythonPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      PoetryCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewPythonOptions, PythonOptions.ValidateBuild),
+		Help:           "install python dependencies with poetry resolving them from Artifactory",
+		Builder:        pythonBuildCommand(PoetryCmd),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          PoetryCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewPythonOptions, PythonOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "upload python distributions and publish build info",
+		Builder:            jfBuilder(GetPythonPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      DotnetCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewDotnetOptions, DotnetOptions.ValidateBuild),
+		Help:           "restore and build a .NET solution resolving packages from Artifactory",
+		Builder:        dotnetBuildCommand(DotnetCmd),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          DotnetCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewDotnetOptions, DotnetOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "upload NuGet packages and publish build info",
+		Builder:            jfBuilder(GetDotnetPublishCommand),
+	},
+	{
+		Name:           "build",
+		BuildTool:      NugetCmd,
+		Aliases:        []string{""},
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewDotnetOptions, DotnetOptions.ValidateBuild),
+		Help:           "restore NuGet packages from Artifactory",
+		Builder:        dotnetBuildCommand(NugetCmd),
+	},
+	{
+		Name:               Publish,
+		BuildTool:          NugetCmd,
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewDotnetOptions, DotnetOptions.ValidatePublish),
+		PublishesBuildInfo: true,
+		Help:               "upload NuGet packages and publish build info",
+		Builder:            jfBuilder(GetDotnetPublishCommand),
+	},
+	{
+		Name:            "upload",
+		Aliases:         []string{"u"},
+		RequiredFields:  []string{"PLUGIN_URL"},
+		ExclusiveFields: [][]string{{"PLUGIN_SPEC", "PLUGIN_SOURCE"}, {"PLUGIN_SPEC", "PLUGIN_TARGET_PROPS"}},
+		FileFields:      []string{"PLUGIN_SPEC"},
+		Help:            "upload files to Artifactory",
+		Validate:        validateOptions(NewUploadOptions, UploadOptions.Validate),
+		Builder:         jfBuilder(GetUploadCommandArgs),
+	},
+	{
+		Name:            "download",
+		Aliases:         []string{"dl"},
+		RequiredFields:  []string{"PLUGIN_URL"},
+		ExclusiveFields: [][]string{{"PLUGIN_SPEC", "PLUGIN_SPEC_PATH"}},
+		FileFields:      []string{"PLUGIN_SPEC_PATH"},
+		Help:            "download files from Artifactory",
+		Builder:         jfBuilder(GetDownloadCommandArgs),
+	},
+	{
+		Name:               "docker-push",
+		RequiredFields:     []string{"PLUGIN_URL"},
+		Validate:           validateOptions(NewDockerOptions, DockerOptions.Validate),
+		PublishesBuildInfo: true,
+		Help:               "push a docker image to Artifactory and publish its build info",
+		Builder:            jfBuilder(GetDockerPushCommandArgs),
+	},
+	{
+		Name:           "docker-pull",
+		RequiredFields: []string{"PLUGIN_URL"},
+		Validate:       validateOptions(NewDockerOptions, DockerOptions.Validate),
+		Help:           "pull a docker image from Artifactory, publishing its build info when build_name and build_number are set",
+		Builder:        jfBuilder(GetDockerPullCommandArgs),
+	},
+	{
+		Name:               "helm-publish",
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewHelmOptions, HelmOptions.Validate),
+		PublishesBuildInfo: true,
+		Help:               "package a helm chart, upload it to Artifactory and publish build info",
+		Builder:            GetHelmPublishCommandArgs,
+	},
+	{
+		Name:               "terraform-publish",
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:           validateOptions(NewTfOptions, TfOptions.Validate),
+		PublishesBuildInfo: true,
+		Help:               "publish terraform modules to Artifactory and publish build info",
+		Builder:            GetTfPublishCommandArgs,
+	},
+	{
+		Name:           "cleanup",
+		Aliases:        []string{"build-clean"},
+		RequiredFields: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		NoAuth:         true,
+		Help:           "clean the locally collected build info",
+		Builder:        jfBuilder(GetCleanupCommandArgs),
+	},
+	{
+		Name:           "scan",
+		Aliases:        []string{"build-scan"},
+		RequiredFields: []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Help:           "scan a published build with Xray",
+		Builder:        jfBuilder(GetScanCommandArgs),
+	},
+	{
+		Name:               "publish-build-info",
+		Aliases:            []string{BuildPublish},
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		PublishesBuildInfo: true,
+		Help:               "publish the collected build info",
+		Builder:            jfBuilder(GetBuildInfoPublishCommandArgs),
+	},
+	{
+		Name:           "promote",
+		Aliases:        []string{"build-promote"},
+		RequiredFields: []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		Validate:       validateOptions(NewPromoteOptions, PromoteOptions.Validate),
+		Help:           "promote a published build to a target repository",
+		Builder:        jfBuilder(GetPromoteCommandArgs),
+	},
+	{
+		Name:               "add-build-dependencies",
+		Aliases:            []string{"build-add-dependencies"},
+		RequiredFields:     []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		PublishesBuildInfo: true,
+		Help:               "add dependencies to a build and publish its build info",
+		Builder:            jfBuilder(GetAddDependenciesCommandArgs),
+	},
+	{
+		// Used only by standalone step of build-discard
+		Name:           "build-discard",
+		Aliases:        []string{"discard"},
+		RequiredFields: []string{"PLUGIN_URL", "PLUGIN_BUILD_NAME"},
+		Validate:       validateOptions(NewDiscardOptions, DiscardOptions.Validate),
+		Help:           "discard old builds from Artifactory",
+		Builder:        jfBuilder(GetBuildDiscardCommandArgs),
+	},
+}
+
+// LookupRtCommand returns the registered command for the build tool and
+// command name, or an error listing the valid choices.
+func LookupRtCommand(buildTool, command string) (*RtCommand, error) {
+	buildTool = normalizeRtName(buildTool)
+	command = normalizeRtName(command)
+	if alias, ok := rtBuildToolAliases[buildTool]; ok {
+		buildTool = alias
+	}
+
+	if buildTool != "" && !isRtBuildTool(buildTool) {
+		return nil, fmt.Errorf("unknown build_tool %q, valid build tools are: %s",
+			buildTool, strings.Join(rtBuildTools(), ", "))
+	}
+	if command == validateCommand {
+		// handled by execPlugin before any command is looked up
+		return nil, fmt.Errorf("command %q only runs on its own, set it as PLUGIN_COMMAND or set PLUGIN_VALIDATE_ONLY", command)
+	}
+
+	// commands specific to the build tool take precedence over generic ones
+	for _, scope := range []string{buildTool, ""} {
+		for i := range RtCommandRegistry {
+			rtCmd := &RtCommandRegistry[i]
+			if rtCmd.BuildTool == scope && rtCmd.matches(command) {
+				return rtCmd, nil
+			}
+		}
+	}
+
+	if buildTool != "" {
+		return nil, fmt.Errorf("unknown command %q for build_tool %q, valid commands are: %s",
+			command, buildTool, strings.Join(rtCommandNames(buildTool), ", "))
+	}
+	return nil, fmt.Errorf("unknown command %q, valid commands are: %s",
+		command, strings.Join(rtCommandNames(""), ", "))
+}
+
+// stepRtCommands returns the registered commands the step runs, those of
+// PLUGIN_COMMANDS or PLUGIN_COMMAND. Unknown commands are left out, they are
+// reported when the commands are built.
+func stepRtCommands(args Args) []*RtCommand {
+	names := args.Commands
+	if len(names) == 0 {
+		if args.BuildTool == "" && args.Command == "" {
+			return nil
+		}
+		names = []string{args.Command}
+	}
+	var rtCmds []*RtCommand
+	for _, name := range names {
+		if rtCmd, err := LookupRtCommand(args.BuildTool, strings.TrimSpace(name)); err == nil {
+			rtCmds = append(rtCmds, rtCmd)
+		}
+	}
+	return rtCmds
+}
+
+// RtCommandsHelp returns a usage table of the registered commands.
+func RtCommandsHelp() string {
+	var sb strings.Builder
+	for _, rtCmd := range RtCommandRegistry {
+		name := rtCmd.Name
+		if rtCmd.BuildTool != "" {
+			name = rtCmd.BuildTool + " " + name
+		}
+		fmt.Fprintf(&sb, "  %-28s %s\n", name, rtCmd.Help)
+	}
+	fmt.Fprintf(&sb, "  %-28s %s\n", validateCommand, validateHelp)
+	return sb.String()
+}
+
+// MissingFields returns the envconfig tags of the required fields that are
+// not set in args.
+func (c *RtCommand) MissingFields(args Args) []string {
+	var missing []string
+	v := reflect.ValueOf(args)
+	tagMap := getTagMapping(v.Type())
+	for _, tag := range c.RequiredFields {
+		fieldIndex, found := tagMap[tag]
+		if !found || v.Field(fieldIndex).IsZero() {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
+
+func (c *RtCommand) matches(command string) bool {
+	if c.Name == command {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if alias == command {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeRtName(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func isRtBuildTool(buildTool string) bool {
+	for _, rtCmd := range RtCommandRegistry {
+		if rtCmd.BuildTool == buildTool {
+			return true
+		}
+	}
+	return false
+}
+
+func rtBuildTools() []string {
+	var tools []string
+	seen := map[string]bool{}
+	for _, rtCmd := range RtCommandRegistry {
+		if rtCmd.BuildTool != "" && !seen[rtCmd.BuildTool] {
+			seen[rtCmd.BuildTool] = true
+			tools = append(tools, rtCmd.BuildTool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// rtCommandNames lists the commands available for the build tool, including
+// the generic commands that do not depend on one and validate.
+func rtCommandNames(buildTool string) []string {
+	names := []string{validateCommand}
+	seen := map[string]bool{validateCommand: true}
+	for _, rtCmd := range RtCommandRegistry {
+		if rtCmd.BuildTool != buildTool && rtCmd.BuildTool != "" {
+			continue
+		}
+		if !seen[rtCmd.Name] {
+			seen[rtCmd.Name] = true
+			names = append(names, rtCmd.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
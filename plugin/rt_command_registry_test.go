@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookupRtCommand(t *testing.T) {
+	tests := []struct {
+		buildTool string
+		command   string
+		wantName  string
+		wantTool  string
+		wantErr   string
+	}{
+		{buildTool: "mvn", command: "", wantName: "build", wantTool: MvnCmd},
+		{buildTool: "maven", command: "publish", wantName: Publish, wantTool: MvnCmd},
+		{buildTool: "gradle", command: "build", wantName: "build", wantTool: GradleCmd},
+		{buildTool: "gradle", command: "download", wantName: "download"},
+		{buildTool: "", command: "build-promote", wantName: "promote"},
+		{buildTool: "", command: " Scan ", wantName: "scan"},
+		{buildTool: "", command: "promte", wantErr: "unknown command \"promte\", valid commands are: " +
+			"add-build-dependencies, build-discard, cleanup, docker-pull, docker-push, download, helm-publish, promote, publish-build-info, scan, terraform-publish, upload, validate"},
+		{buildTool: "gradel", command: "build", wantErr: "unknown build_tool \"gradel\", valid build tools are: dotnet, go, gradle, mvn, npm, nuget, pip, pipenv, pnpm, poetry, yarn"},
+		{buildTool: "mvn", command: "deploy", wantErr: "unknown command \"deploy\" for build_tool \"mvn\""},
+		{buildTool: "mvn", command: "validate", wantErr: "command \"validate\" only runs on its own"},
+	}
+
+	for _, tc := range tests {
+		rtCmd, err := LookupRtCommand(tc.buildTool, tc.command)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LookupRtCommand(%q, %q): expected error containing %q, got %v",
+					tc.buildTool, tc.command, tc.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("LookupRtCommand(%q, %q): unexpected error: %v", tc.buildTool, tc.command, err)
+			continue
+		}
+		if rtCmd.Name != tc.wantName || rtCmd.BuildTool != tc.wantTool {
+			t.Errorf("LookupRtCommand(%q, %q): expected %s/%s, got %s/%s", tc.buildTool, tc.command,
+				tc.wantTool, tc.wantName, rtCmd.BuildTool, rtCmd.Name)
+		}
+	}
+}
+
+func TestRtCommandsHelpListsValidate(t *testing.T) {
+	help := RtCommandsHelp()
+	for _, want := range []string{"mvn publish", "upload", validateCommand + " "} {
+		if !strings.Contains(help, "  "+want) {
+			t.Errorf("Expected the help to list %q, got:\n%s", want, help)
+		}
+	}
+}
+
+func TestGetRtCommandsListUnknownCommand(t *testing.T) {
+	args := Args{
+		Command:     "promte",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	cmdList, err := GetRtCommandsList(args)
+	if err == nil {
+		t.Fatalf("Expected error for unknown command, got %d commands", len(cmdList))
+	}
+	if len(cmdList) != 0 {
+		t.Errorf("Expected no commands, got %d", len(cmdList))
+	}
+}
+
+func TestGetRtCommandsListMissingFields(t *testing.T) {
+	args := Args{
+		Command:   "promote",
+		Username:  "ab",
+		Password:  "cd",
+		URL:       RtUrlTestStr,
+		BuildName: RtBuildName,
+		Target:    "promoted-repo",
+	}
+	_, err := GetRtCommandsList(args)
+	want := "missing mandatory fields for command \"promote\": PLUGIN_BUILD_NUMBER"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+}
+
+func TestRtCommandRegistryRequiredFieldsExist(t *testing.T) {
+	for _, rtCmd := range RtCommandRegistry {
+		for _, tag := range rtCmd.RequiredFields {
+			if _, found := getTagMapping(reflect.TypeOf(Args{}))[tag]; !found {
+				t.Errorf("command %s %s requires unknown field %s", rtCmd.BuildTool, rtCmd.Name, tag)
+			}
+		}
+	}
+}
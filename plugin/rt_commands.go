@@ -1,12 +1,13 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -21,36 +22,192 @@ const (
 	Publish      = "publish"
 	GradleConfig = "gradle-config"
 	GradleCmd    = "gradle"
+	NpmConfig    = "npm-config"
+	NpmCmd       = "npm"
+	YarnConfig   = "yarn-config"
+	YarnCmd      = "yarn"
+	PnpmCmd      = "pnpm"
+	GoConfig     = "go-config"
+	GoCmd        = "go"
+	GoPublish    = "go-publish"
+	PipCmd       = "pip"
+	PipenvCmd    = "pipenv"
+	PoetryCmd    = "poetry"
+	DotnetCmd    = "dotnet"
+	NugetCmd     = "nuget"
+	DockerCmd    = "docker"
+	HelmCmd      = "helm"
+	TfConfig     = "terraform-config"
+	TfCmd        = "terraform"
 	tmpServerId  = "tmpServerId"
 )
 
-func HandleRtCommands(args Args) error {
+func HandleRtCommands(ctx context.Context, args Args, executor Executor) error {
 
-	commandsList, err := GetRtCommandsList(args)
+	steps, err := GetRtCommandSteps(args)
 	if err != nil {
 		logrus.Println("Error Unable to get rt commands list err = ", err)
 		return err
 	}
 
+	if args.PublishBuildInfo {
+		// register the server build info is published to once, up front
+		serverCmdArgs, err := GetPublishBuildInfoServerCommandArgs(args)
+		if err != nil {
+			return err
+		}
+		steps[0].CmdList = append([]Command{{Args: serverCmdArgs}}, steps[0].CmdList...)
+	}
+
+	if args.DryRun {
+		var plan []Command
+		for _, step := range steps {
+			for _, cmd := range step.CmdList {
+				plan = append(plan, cmd)
+				if _, ok := configAddServerId(cmd); ok {
+					continue
+				}
+				if args.PublishBuildInfo {
+					publishCmdArgs, err := GetPublishBuildInfoCommandArgs(args)
+					if err != nil {
+						return err
+					}
+					plan = append(plan, Command{Args: publishCmdArgs})
+				}
+			}
+		}
+		PrintDryRunPlan(os.Stdout, args, withBuildInfoSteps(args, plan))
+		return nil
+	}
+
 	err = WriteKnownGoodServerCertsForTls(args)
 	if err != nil {
 		logrus.Println("Error Unable to write TLS certs err = ", err)
 		return err
 	}
 
-	for _, cmd := range commandsList {
-		execArgs := []string{getJfrogBin()}
-		execArgs = append(execArgs, cmd...)
-		err := ExecCommand(args, execArgs)
-		if err != nil {
-			logrus.Println("Error Unable to run err = ", err)
-			return err
+	outputs := NewStepOutputs()
+	defer writeStepOutputs(outputs)
+	var registered []string
+	defer func() { removeRegisteredServers(ctx, executor, args, registered) }()
+
+	results := make([]string, len(steps))
+	if len(args.Commands) > 0 {
+		defer func() { logRtCommandSummary(steps, results) }()
+	}
+
+	for i, step := range steps {
+		stepArgs := args
+		stepArgs.Command = step.Name
+		for _, cmd := range step.CmdList {
+			if err := ExecCommand(ctx, executor, stepArgs, cmd, outputs); err != nil {
+				logrus.Println("Error Unable to run err = ", err)
+				results[i] = "failed"
+				return err
+			}
+			if serverId, ok := configAddServerId(cmd); ok {
+				registered = append(registered, serverId)
+			}
 		}
+		results[i] = "succeeded"
 	}
 
 	return err
 }
 
+// RtCommandStep is one of the plugin commands run by a step, with its
+// commands.
+type RtCommandStep struct {
+	Name    string
+	CmdList []Command
+}
+
+// GetRtCommandSteps returns the commands of PLUGIN_COMMANDS in order, or the
+// single PLUGIN_COMMAND. Every command is validated before any runs, and a
+// server registered by an earlier command is not registered again.
+func GetRtCommandSteps(args Args) ([]RtCommandStep, error) {
+	if len(args.Commands) == 0 {
+		cmdList, err := GetRtCommandsList(args)
+		if err != nil {
+			return nil, err
+		}
+		return []RtCommandStep{{Name: args.Command, CmdList: cmdList}}, nil
+	}
+
+	var steps []RtCommandStep
+	registered := map[string]bool{}
+	for _, name := range args.Commands {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		stepArgs := args
+		stepArgs.Command = name
+		cmdList, err := GetRtCommandsList(stepArgs)
+		if err != nil {
+			return nil, fmt.Errorf("command %q: %w", name, err)
+		}
+
+		var stepCmdList []Command
+		for _, cmd := range cmdList {
+			if serverId, ok := configAddServerId(cmd); ok {
+				if registered[serverId] {
+					continue
+				}
+				registered[serverId] = true
+			}
+			stepCmdList = append(stepCmdList, cmd)
+		}
+		steps = append(steps, RtCommandStep{Name: name, CmdList: stepCmdList})
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no command set in PLUGIN_COMMANDS")
+	}
+	return steps, nil
+}
+
+// configAddServerId returns the id of the server registered by cmd when it
+// is a jf config add command.
+func configAddServerId(cmd Command) (string, bool) {
+	if cmd.IsJf() && len(cmd.Args) > 2 && cmd.Args[0] == "config" && cmd.Args[1] == "add" {
+		return cmd.Args[2], true
+	}
+	return "", false
+}
+
+// logRtCommandSummary logs the outcome of every command of the step.
+func logRtCommandSummary(steps []RtCommandStep, results []string) {
+	var sb strings.Builder
+	sb.WriteString("Command summary:\n")
+	for i, step := range steps {
+		result := results[i]
+		if result == "" {
+			result = "skipped"
+		}
+		fmt.Fprintf(&sb, "  %-28s %s\n", step.Name, result)
+	}
+	logrus.Print(sb.String())
+}
+
+// removeRegisteredServers removes the temporary servers added by the run
+// from a JFrog CLI home shared with other runs. Servers with an id set by the
+// user, such as PLUGIN_DEPLOYER_ID, are kept. Private homes are deleted as a
+// whole.
+func removeRegisteredServers(ctx context.Context, executor Executor, args Args, serverIds []string) {
+	if args.cliHomeDir != "" || args.workDir == "" {
+		return
+	}
+	for _, serverId := range serverIds {
+		if !isRunServerID(args, serverId) {
+			continue
+		}
+		removeArgs := []string{getJfrogBin(), "config", "remove", serverId, "--quiet"}
+		if _, err := runCommand(context.WithoutCancel(ctx), executor, args, removeArgs, ""); err != nil {
+			logrus.Println("Error removing server config ", serverId, " err = ", err)
+		}
+	}
+}
+
 func WriteKnownGoodServerCertsForTls(args Args) error {
 
 	insecure := parseBoolOrDefault(false, args.Insecure)
@@ -62,7 +219,9 @@ func WriteKnownGoodServerCertsForTls(args Args) error {
 	if args.PEMFileContents != "" {
 		var path string
 		// figure out path to write pem file
-		if args.PEMFilePath == "" {
+		if args.PEMFilePath == "" && args.cliHomeDir != "" {
+			path = filepath.Join(args.cliHomeDir, "security", "certs", "cert.pem")
+		} else if args.PEMFilePath == "" {
 			if runtime.GOOS == "windows" {
 				path = "C:/users/ContainerAdministrator/.jfrog/security/certs/cert.pem"
 			} else {
@@ -91,68 +250,27 @@ func WriteKnownGoodServerCertsForTls(args Args) error {
 	return nil
 }
 
-func GetRtCommandsList(args Args) ([][]string, error) {
+func GetRtCommandsList(args Args) ([]Command, error) {
 	logrus.Println("Handling rt command handleRtCommand")
-	commandsList := [][]string{}
-	var err error
-
 	logrus.Println("Checking GetRtCommandsList args.Command ", args.Command)
 
-	if args.BuildTool == MvnCmd && (args.Command == "" || args.Command == "build") {
-		logrus.Println("mvn build start")
-		commandsList, err = GetMavenBuildCommandArgs(args)
-	}
-
-	if args.BuildTool == MvnCmd && args.Command == "publish" {
-		commandsList, err = GetMavenPublishCommand(args)
-	}
-
-	if args.BuildTool == GradleCmd && (args.Command == "" || args.Command == "build") {
-		logrus.Println("Gradle build start")
-		commandsList, err = GetGradleCommandArgs(args)
-	}
-
-	if args.BuildTool == GradleCmd && args.Command == "publish" {
-		logrus.Println("Gradle publish start")
-		commandsList, err = GetGradlePublishCommand(args)
-	}
-
-	if args.Command == "download" {
-		logrus.Println("download start")
-		commandsList, err = GetDownloadCommandArgs(args)
-	}
-
-	if args.Command == "cleanup" {
-		logrus.Println("cleanup start")
-		commandsList, err = GetCleanupCommandArgs(args)
-	}
-
-	if args.Command == "scan" {
-		logrus.Println("scan start")
-		commandsList, err = GetScanCommandArgs(args)
-	}
-
-	if args.Command == "publish-build-info" {
-		logrus.Println("publish-build-info start")
-		commandsList, err = GetBuildInfoPublishCommandArgs(args)
-	}
-
-	if args.Command == "promote" {
-		logrus.Println("promote start")
-		commandsList, err = GetPromoteCommandArgs(args)
+	rtCmd, err := LookupRtCommand(args.BuildTool, args.Command)
+	if err != nil {
+		logrus.Printf("Supported commands:\n%s", RtCommandsHelp())
+		return []Command{}, err
 	}
 
-	if args.Command == "add-build-dependencies" {
-		logrus.Println("add-build-dependencies start")
-		commandsList, err = GetAddDependenciesCommandArgs(args)
+	if missing := rtCmd.MissingFields(args); len(missing) > 0 {
+		return []Command{}, fmt.Errorf("missing mandatory fields for command %q: %s",
+			rtCmd.Name, strings.Join(missing, ", "))
 	}
 
-	// command "build-discard" Used only by standalone step of build-discard
-	if args.Command == "build-discard" {
-		logrus.Println("build-discard start")
-		commandsList, err = GetBuildDiscardCommandArgs(args)
+	logrus.Println(rtCmd.Name, "start")
+	cmdList, err := rtCmd.Builder(args)
+	if err != nil {
+		return cmdList, err
 	}
-	return commandsList, err
+	return withBuildInfoSteps(args, cmdList), nil
 }
 
 func GetShellForOs(osName string) (string, string) {
@@ -170,32 +288,32 @@ func GetShellForOs(osName string) (string, string) {
 	return "sh", "-c"
 }
 
-func ExecCommand(args Args, cmdArgs []string) error {
-
-	cmdStr := strings.Join(cmdArgs[:], " ")
-
-	shell, shArg := GetShellForOs(runtime.GOOS)
+func ExecCommand(ctx context.Context, executor Executor, args Args, cmd Command, outputs *StepOutputs) error {
 
 	logrus.Println()
-	logrus.Printf("%s %s %s", shell, shArg, cmdStr)
+	logrus.Println(cmd.String())
 	logrus.Println()
 
-	cmd := exec.Command(shell, shArg, cmdStr)
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	trace(cmd)
-
-	err := cmd.Run()
+	cmdArgs := cmd.Argv()
+	result, err := runCommandWithRetry(ctx, executor, args, cmdArgs, cmd.Dir)
+	outputs.Collect(args, cmdArgs, result, err)
+	if isBuildAddGit(cmdArgs) {
+		// missing VCS details must not prevent publishing the build info
+		if err != nil {
+			logrus.Println("Unable to add git info to the build info: ", err)
+		}
+		return nil
+	}
 	if err != nil {
 		logrus.Println(" Error: ", err)
 		return err
 	}
+	if isBuildCollectEnv(cmdArgs) || jfSubcommand(cmdArgs) == "config" {
+		return nil
+	}
 
 	if args.PublishBuildInfo {
-		if err := publishBuildInfo(args); err != nil {
+		if err := publishBuildInfo(ctx, executor, args, outputs); err != nil {
 			logrus.Println("Error publishing build info: ", err)
 			return err
 		}
@@ -217,37 +335,61 @@ func PopulateArgs(tmpCommandsList *[]string, args *Args,
 	for _, jsonTagToExeFlagMapStringItem := range jsonTagToExeFlagMapStringItemList {
 		flagName := jsonTagToExeFlagMapStringItem.FlagName
 		pluginArgJsonTag := jsonTagToExeFlagMapStringItem.PluginArgJsonTag
-		pluginArgValue, err := GetFieldAddress[*Args, string](args, pluginArgJsonTag)
+		pluginArgValue, err := getFlagValue(args, pluginArgJsonTag)
 
 		if err != nil {
 			if jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
-				logrus.Println("GetFieldAddress error: ", err)
+				logrus.Println("getFlagValue error: ", err)
 				return err
 			}
-			//logrus.Println("GetFieldAddress error: ", err)
 			continue
 		}
 
-		if pluginArgValue == nil {
-			if jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
-				logrus.Println("missing mandatory field: ", pluginArgJsonTag)
-				return fmt.Errorf("missing mandatory field %s", pluginArgJsonTag)
-			}
-			logrus.Println("missing mandatory field: ", pluginArgJsonTag)
-			continue
-		}
-
-		if pluginArgValue == nil &&
-			jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
+		if pluginArgValue == "" && jsonTagToExeFlagMapStringItem.IsMandatory {
 			logrus.Println("missing mandatory field: ", pluginArgJsonTag)
 			return fmt.Errorf("missing mandatory field %s", pluginArgJsonTag)
 		}
-		AppendStringArg(tmpCommandsList, flagName, pluginArgValue)
+		AppendStringArg(tmpCommandsList, flagName, &pluginArgValue)
 	}
 
 	return nil
 }
 
+// getFlagValue returns the flag value of the Args field tagged argJsonTag,
+// empty when the field is unset: strings as is, non-zero ints, true bools
+// and set *bool fields.
+func getFlagValue(args *Args, argJsonTag string) (string, error) {
+	v := reflect.ValueOf(args).Elem()
+	fieldIndex, found := getTagMapping(v.Type())[argJsonTag]
+	if !found {
+		return "", fmt.Errorf("field with tag '%s' not found in struct type '%s'", argJsonTag, v.Type().Name())
+	}
+
+	field := v.Field(fieldIndex)
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Int() == 0 {
+			return "", nil
+		}
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Bool:
+		if !field.Bool() {
+			return "", nil
+		}
+		return "true", nil
+	case reflect.Ptr:
+		if field.Type().Elem().Kind() == reflect.Bool {
+			if field.IsNil() {
+				return "", nil
+			}
+			return strconv.FormatBool(field.Elem().Bool()), nil
+		}
+	}
+	return "", fmt.Errorf("field with tag '%s' has unsupported type %s", argJsonTag, field.Type())
+}
+
 func AppendStringArg(argsList *[]string, argName string, argValue *string) {
 
 	if argsList == nil {
@@ -341,12 +483,7 @@ func GetConfigAddConfigCommandArgs(srvConfigStr, userName, password, url,
 }
 
 func IsBuildDiscardArgs(args Args) bool {
-	if len(args.Async) > 0 ||
-		len(args.DeleteArtifacts) > 0 ||
-		len(args.ExcludeBuilds) > 0 ||
-		len(args.MaxBuilds) > 0 ||
-		len(args.MaxDays) > 0 {
-		return true
-	}
-	return false
+	opts, err := NewDiscardOptions(args)
+	// invalid settings are reported by GetBuildDiscardCommandArgs
+	return err != nil || opts.IsSet()
 }
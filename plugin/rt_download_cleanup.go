@@ -21,15 +21,15 @@ func GetDownloadCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 	downloadCommandArgs := []string{"rt", "download"}
 
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username,
-		Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
+	serverId := serverID(args, tmpServerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
 
 	if args.Spec != "" {
-		fileName := getTimestampedFileName()
-		err = writeToFile(fileName, args.Spec)
+		fileName, err := writeTempFile(args, getTimestampedFileName(), args.Spec)
 		if err != nil {
 			return cmdList, err
 		}
@@ -37,15 +37,19 @@ func GetDownloadCommandArgs(args Args) ([][]string, error) {
 		args.SpecPath = fileName
 	}
 
-	downloadCommandArgs = append(downloadCommandArgs, authParams...)
-	downloadCommandArgs = append(downloadCommandArgs, args.Target, args.Source)
-	downloadCommandArgs = append(downloadCommandArgs)
+	downloadCommandArgs = append(downloadCommandArgs, "--server-id="+serverId)
+	for _, arg := range []string{args.Target, args.Source} {
+		if arg != "" {
+			downloadCommandArgs = append(downloadCommandArgs, arg)
+		}
+	}
 
 	err = PopulateArgs(&downloadCommandArgs, &args, DownloadCmdJsonTagToExeFlagMapStringItemList)
 	if err != nil {
 		return cmdList, err
 	}
 
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, downloadCommandArgs)
 	return cmdList, nil
 }
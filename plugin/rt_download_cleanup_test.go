@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"os"
 	"strings"
 	"testing"
 )
@@ -28,7 +29,8 @@ func TestGetDownloadCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt download --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD   " + "--build-name=t2 --build-number=v1.0 " +
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
+		"rt download --server-id=tmpServerId " + "--build-name=t2 --build-number=v1.0 " +
 			"--module=backend_module --project=backend_project --url=https://artifactory.test.io/artifactory/ --spec=spec.json",
 	}
 
@@ -57,7 +59,8 @@ func TestGetDownloadCommandUserAccessToken(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt download --access-token $PLUGIN_ACCESS_TOKEN   --build-name=t2 --build-number=v1.0 --module=backend_module" +
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --access-token-stdin --interactive=false",
+		"rt download --server-id=tmpServerId --build-name=t2 --build-number=v1.0 --module=backend_module" +
 			" --project=backend_project --url=https://artifactory.test.io/artifactory/ --spec=spec.json",
 	}
 
@@ -94,3 +97,57 @@ func TestGetCleanupCommandUserPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestGetDownloadCommandSpecNotWrittenInDryRun(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	args := Args{
+		AccessToken: RtAccessToken,
+		Command:     "download",
+		URL:         RtUrlTestStr,
+		Spec:        `{"files":[{"pattern":"repo/*"}]}`,
+		DryRun:      true,
+	}
+	cmdList, err := GetDownloadCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdList[1], " "), "--spec=") {
+		t.Errorf("Expected a --spec flag, got %q", cmdList[1])
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no spec file to be written in a dry run, got %d files", len(entries))
+	}
+}
+
+func TestGetDownloadCommandSpecWrittenToRunDir(t *testing.T) {
+	args := Args{
+		AccessToken: RtAccessToken,
+		Command:     "download",
+		URL:         RtUrlTestStr,
+		Spec:        `{"files":[{"pattern":"repo/*"}]}`,
+		workDir:     t.TempDir(),
+	}
+	cmdList, err := GetDownloadCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	specPath := ""
+	for _, arg := range cmdList[1] {
+		if strings.HasPrefix(arg, "--spec=") {
+			specPath = strings.TrimPrefix(arg, "--spec=")
+		}
+	}
+	content, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("Expected the spec to be written to the run directory: %v", err)
+	}
+	if string(content) != args.Spec {
+		t.Errorf("Expected spec %q, got %q", args.Spec, content)
+	}
+}
@@ -12,16 +12,23 @@ func GetScanCommandArgs(args Args) ([][]string, error) {
 		return cmdList, errors.New("Valid BuildName and BuildNumber are required")
 	}
 
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username,
-		Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
+	tmpServerId := serverID(args, tmpServerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
 
 	scanCommandArgs := []string{
 		"build-scan", args.BuildName, args.BuildNumber}
-	scanCommandArgs = append(scanCommandArgs, "--url="+args.URL)
-	scanCommandArgs = append(scanCommandArgs, authParams...)
+	scanCommandArgs = append(scanCommandArgs, "--server-id="+tmpServerId)
+	if err := errors.Join(checkFormat(args.Format)...); err != nil {
+		return cmdList, err
+	}
+	if args.Format != "" {
+		scanCommandArgs = append(scanCommandArgs, "--format="+args.Format)
+	}
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, scanCommandArgs)
 
 	return cmdList, nil
@@ -30,14 +37,15 @@ func GetScanCommandArgs(args Args) ([][]string, error) {
 func GetBuildInfoPublishCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
-	tmpServerId := tmpServerId
+	tmpServerId := serverID(args, tmpServerId)
 	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
 		return cmdList, err
 	}
-	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber}
+	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber,
+		"--server-id=" + tmpServerId}
 	err = PopulateArgs(&buildInfoCommandArgs, &args, nil)
 	if err != nil {
 		return cmdList, err
@@ -50,17 +58,25 @@ func GetBuildInfoPublishCommandArgs(args Args) ([][]string, error) {
 func GetPromoteCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
-	promoteCommandArgs := []string{"rt", "build-promote"}
-	if args.Copy != "" {
-		promoteCommandArgs = append(promoteCommandArgs, "--copy="+args.Copy)
+	opts, err := NewPromoteOptions(args)
+	if err != nil {
+		return cmdList, err
 	}
-	promoteCommandArgs = append(promoteCommandArgs, "--url="+args.URL)
-	promoteCommandArgs = append(promoteCommandArgs, args.BuildName, args.BuildNumber, args.Target)
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username, Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return cmdList, err
+	}
+
+	tmpServerId := serverID(args, tmpServerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
-	promoteCommandArgs = append(promoteCommandArgs, authParams...)
+
+	promoteCommandArgs := append([]string{"rt", "build-promote"}, opts.Flags()...)
+	promoteCommandArgs = append(promoteCommandArgs, "--server-id="+tmpServerId)
+	promoteCommandArgs = append(promoteCommandArgs, args.BuildName, args.BuildNumber, opts.Target)
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, promoteCommandArgs)
 	return cmdList, nil
 }
@@ -77,6 +93,7 @@ var AddDependenciesCmdJsonToExeFlagMapItemList = []JsonTagToExeFlagMapStringItem
 
 func GetAddDependenciesCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
+	tmpServerId := serverID(args, tmpServerId)
 
 	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
@@ -96,7 +113,8 @@ func GetAddDependenciesCommandArgs(args Args) ([][]string, error) {
 		addDependenciesCommandArgs = append(addDependenciesCommandArgs, args.DependencyPattern)
 	}
 
-	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber}
+	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber,
+		"--server-id=" + tmpServerId}
 	err = PopulateArgs(&buildInfoCommandArgs, &args, nil)
 	if err != nil {
 		return cmdList, err
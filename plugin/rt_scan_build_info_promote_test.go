@@ -20,7 +20,8 @@ func TestGetScanCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"build-scan t2 v1.0",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
+		"build-scan t2 v1.0 --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
@@ -31,6 +32,38 @@ func TestGetScanCommandUserPassword(t *testing.T) {
 	}
 }
 
+func TestGetScanCommandFormat(t *testing.T) {
+	t.Setenv("DRONE_OUTPUT", "output.env")
+	args := Args{
+		AccessToken: RtAccessToken,
+		Command:     "scan",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		URL:         RtUrlTestStr,
+	}
+	cmdList, err := GetScanCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(cmdList[1], " "); strings.Contains(got, "--format") {
+		t.Errorf("Expected the default output of the scan, got %s", got)
+	}
+
+	args.Format = "json"
+	cmdList, err = GetScanCommandArgs(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, want := strings.Join(cmdList[1], " "), "build-scan t2 v1.0 --server-id=tmpServerId --format=json"; got != want {
+		t.Errorf("Expected: %s, Got: %s", want, got)
+	}
+
+	args.Format = "xml"
+	if _, err := GetScanCommandArgs(args); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
 func TestGetBuildInfoPublishCommandUserPassword(t *testing.T) {
 	args := Args{
 		Username:    "ab",
@@ -49,9 +82,9 @@ func TestGetBuildInfoPublishCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME " +
-			"--password $PLUGIN_PASSWORD --interactive=false",
-		"rt build-publish t2 v1.0",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab " +
+			"--password-stdin --interactive=false",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
@@ -79,8 +112,8 @@ func TestPromoteBuildCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt build-promote --copy=true --url=https://artifactory.test.io/artifactory/ t2 v1.0 promoted-repo " +
-			"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
+		"rt build-promote --copy=true --server-id=tmpServerId t2 v1.0 promoted-repo",
 	}
 
 	for i, cmd := range cmdList {
@@ -109,9 +142,9 @@ func TestAddDependenciesCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false",
 		"rt build-add-dependencies --module=backend_module --project=backend_project --spec=spec.json --server-id=tmpServerId t2 v1.0",
-		"rt build-publish t2 v1.0",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
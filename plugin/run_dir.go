@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const jfrogCliHomeDirEnv = "JFROG_CLI_HOME_DIR"
+
+// prepareRunDir creates the private directory of a plugin run and records it
+// in args. Temporary files such as download specs are written to it and,
+// unless JFROG_CLI_HOME_DIR is already set, it also holds the JFrog CLI home
+// so that servers registered by the run never leak into the global ~/.jfrog
+// config. The returned function removes the directory.
+func prepareRunDir(args *Args) (func(), error) {
+	workDir, err := os.MkdirTemp("", "drone-artifactory-")
+	if err != nil {
+		return func() {}, fmt.Errorf("error creating run directory: %s", err)
+	}
+	args.workDir = workDir
+
+	if os.Getenv(jfrogCliHomeDirEnv) == "" {
+		args.cliHomeDir = filepath.Join(workDir, ".jfrog")
+		if err := os.MkdirAll(args.cliHomeDir, 0700); err != nil {
+			os.RemoveAll(workDir)
+			return func() {}, fmt.Errorf("error creating jfrog cli home: %s", err)
+		}
+	}
+
+	return func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			logrus.Println("Error removing run directory: ", err)
+		}
+	}, nil
+}
+
+// newRunID returns a random identifier distinguishing concurrent runs.
+func newRunID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// serverID returns the id of a temporary server registered by the run,
+// unique to the run when a run id is set.
+func serverID(args Args, base string) string {
+	if args.runID == "" {
+		return base
+	}
+	return base + "-" + args.runID
+}
+
+// serverIDOrDefault returns id, or the temporary server id when id is empty.
+func serverIDOrDefault(args Args, id string) string {
+	if id != "" {
+		return id
+	}
+	return serverID(args, tmpServerId)
+}
+
+// isRunServerID reports whether id is a temporary server id generated by the
+// run, as opposed to a server id set by the user.
+func isRunServerID(args Args, id string) bool {
+	return args.runID != "" && strings.HasSuffix(id, "-"+args.runID)
+}
+
+// tempFilePath returns the path of a temporary file, inside the run
+// directory when there is one.
+func tempFilePath(args Args, name string) string {
+	if args.workDir == "" {
+		return name
+	}
+	return filepath.Join(args.workDir, name)
+}
+
+// writeTempFile writes content to a temporary file of the run and returns its
+// path. In a dry run or validation nothing is written and only the path the
+// file would have is returned.
+func writeTempFile(args Args, name, content string) (string, error) {
+	path := tempFilePath(args, name)
+	if args.DryRun || isValidateOnly(args) {
+		return path, nil
+	}
+	if args.workDir == "" {
+		return "", fmt.Errorf("error writing %s: no run directory", name)
+	}
+	return path, writeToFile(path, content)
+}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// envValue returns the value of name in env. Like os/exec, the last
+// occurrence of a duplicated variable wins.
+func envValue(env []string, name string) string {
+	value := ""
+	for _, kv := range env {
+		if strings.HasPrefix(kv, name+"=") {
+			value = strings.TrimPrefix(kv, name+"=")
+		}
+	}
+	return value
+}
+
+func TestHandleRtCommandsUsesPrivateCliHome(t *testing.T) {
+	t.Setenv(jfrogCliHomeDirEnv, "")
+	args := Args{
+		Command:     "publish-build-info",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+
+	var homeDirs []string
+	var commands [][]string
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		homeDir := envValue(env, jfrogCliHomeDirEnv)
+		if _, err := os.Stat(homeDir); err != nil {
+			t.Errorf("Expected cli home %q to exist while running %q", homeDir, argv)
+		}
+		homeDirs = append(homeDirs, homeDir)
+		commands = append(commands, argv)
+		return ExecResult{}, nil
+	})
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(commands) != 2 {
+		t.Fatalf("Expected 2 commands, got %d: %q", len(commands), commands)
+	}
+	if homeDirs[0] == "" || homeDirs[0] != homeDirs[1] {
+		t.Errorf("Expected every command to share one private cli home, got %q", homeDirs)
+	}
+	if _, err := os.Stat(homeDirs[0]); !os.IsNotExist(err) {
+		t.Errorf("Expected cli home %q to be removed after the run", homeDirs[0])
+	}
+
+	serverId := commands[0][3]
+	if !strings.HasPrefix(serverId, tmpServerId+"-") {
+		t.Errorf("Expected a unique temporary server id, got %q", serverId)
+	}
+}
+
+func TestHandleRtCommandsCleansUpOnFailure(t *testing.T) {
+	t.Setenv(jfrogCliHomeDirEnv, "")
+	args := Args{
+		Command:     "download",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		Spec:        `{"files": [{"pattern": "libs-release-local/*.jar", "target": "out/"}]}`,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+
+	var specPath string
+	executor := executorFunc(func(ctx context.Context, argv []string, env []string) (ExecResult, error) {
+		for _, arg := range argv {
+			if strings.HasPrefix(arg, "--spec=") {
+				specPath = strings.TrimPrefix(arg, "--spec=")
+			}
+		}
+		if specPath == "" {
+			return ExecResult{}, nil
+		}
+		if _, err := os.Stat(specPath); err != nil {
+			t.Errorf("Expected spec file %q to exist while running", specPath)
+		}
+		return ExecResult{ExitCode: 1}, os.ErrPermission
+	})
+	if err := ExecWithExecutor(context.Background(), args, executor); err == nil {
+		t.Fatalf("Expected error from failing download")
+	}
+
+	if !filepath.IsAbs(specPath) {
+		t.Errorf("Expected spec file in the run directory, got %q", specPath)
+	}
+	if _, err := os.Stat(specPath); !os.IsNotExist(err) {
+		t.Errorf("Expected spec file %q to be removed after a failed run", specPath)
+	}
+}
+
+func TestHandleRtCommandsRemovesServersFromSharedCliHome(t *testing.T) {
+	t.Setenv(jfrogCliHomeDirEnv, t.TempDir())
+	args := Args{
+		Command:   "build-discard",
+		Username:  "ab",
+		Password:  "cd",
+		URL:       RtUrlTestStr,
+		BuildName: RtBuildName,
+		MaxBuilds: "5",
+	}
+
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	last := executor.argvs[len(executor.argvs)-1]
+	serverId := executor.argvs[0][3]
+	want := []string{"jf", "config", "remove", serverId, "--quiet"}
+	if strings.Join(last, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected %q, got %q", want, last)
+	}
+}
+
+func TestHandleRtCommandsKeepsUserServersInSharedCliHome(t *testing.T) {
+	t.Setenv(jfrogCliHomeDirEnv, t.TempDir())
+	args := Args{
+		BuildTool:   "mvn",
+		Command:     "publish",
+		Username:    "ab",
+		Password:    "cd",
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		DeployerId:  RtDeployerId,
+		MaxBuilds:   "5",
+	}
+
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var removed []string
+	for _, argv := range executor.argvs {
+		if len(argv) > 3 && argv[1] == "config" && argv[2] == "remove" {
+			removed = append(removed, argv[3])
+		}
+	}
+	if len(removed) != 0 {
+		t.Errorf("Expected the servers of the user to be kept, got %q removed", removed)
+	}
+}
+
+func TestWriteTempFile(t *testing.T) {
+	workDir := t.TempDir()
+	path, err := writeTempFile(Args{workDir: workDir}, "spec.json", "{}")
+	if err != nil || path != filepath.Join(workDir, "spec.json") {
+		t.Fatalf("Expected the file to be written to the run directory, got %q, %v", path, err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "{}" {
+		t.Errorf("Expected the file content to be written, got %q, %v", content, err)
+	}
+
+	for _, args := range []Args{{DryRun: true}, {ValidateOnly: true}, {Command: validateCommand}} {
+		if path, err := writeTempFile(args, "spec.json", "{}"); err != nil || path != "spec.json" {
+			t.Errorf("Expected only the path in a dry run or validation, got %q, %v", path, err)
+		}
+	}
+
+	if _, err := writeTempFile(Args{}, "spec.json", "{}"); err == nil {
+		t.Errorf("Expected an error without a run directory")
+	}
+}
@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TfOptions are the settings of jf terraform-config and jf terraform
+// publish.
+type TfOptions struct {
+	ModulesDir     string   `split_words:"true"`
+	Namespace      string   `split_words:"true"`
+	Provider       string   `split_words:"true"`
+	Tag            string   `split_words:"true"`
+	Exclusions     []string `split_words:"true"`
+	RepoDeploy     string   `split_words:"true"`
+	DeployerId     string   `split_words:"true"`
+	ServerIdDeploy string   `split_words:"true"`
+	Global         bool     `split_words:"true"`
+}
+
+// NewTfOptions returns the terraform options of args, overridden by the
+// PLUGIN_TERRAFORM_ environment variables. The tag defaults to the tag of
+// the pipeline.
+func NewTfOptions(args Args) (TfOptions, error) {
+	opts := TfOptions{
+		ModulesDir:     args.ModulesDir,
+		Namespace:      args.Namespace,
+		Provider:       args.Provider,
+		Tag:            valueOrDefault(args.ModuleTag, args.Tag.Name),
+		Exclusions:     splitList(args.Exclusions),
+		RepoDeploy:     args.RepoDeploy,
+		DeployerId:     args.DeployerId,
+		ServerIdDeploy: args.ServerIdDeploy,
+		Global:         args.Global,
+	}
+	err := loadOptions(TfOptionsPrefix, &opts)
+	opts.ModulesDir = valueOrDefault(opts.ModulesDir, ".")
+	return opts, err
+}
+
+// Validate returns the problems of the terraform options.
+func (o TfOptions) Validate() []error {
+	var problems []error
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	if o.Namespace == "" {
+		problems = append(problems, errors.New("PLUGIN_NAMESPACE needs to be set"))
+	}
+	if o.Provider == "" {
+		problems = append(problems, errors.New("PLUGIN_PROVIDER needs to be set"))
+	}
+	if o.Tag == "" {
+		problems = append(problems, errors.New("PLUGIN_MODULE_TAG needs to be set when the pipeline has no tag"))
+	}
+	return problems
+}
+
+// ConfigFlags returns the flags of jf terraform-config.
+func (o TfOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addString("--repo-deploy", o.RepoDeploy)
+	flags.addString("--server-id-deploy", o.ServerIdDeploy)
+	return flags
+}
+
+// PublishFlags returns the flags of jf terraform publish besides the build
+// info flags.
+func (o TfOptions) PublishFlags() []string {
+	var flags cmdFlags
+	flags.addString("--namespace", o.Namespace)
+	flags.addString("--provider", o.Provider)
+	flags.addString("--tag", o.Tag)
+	flags.addList("--exclusions", o.Exclusions, ";")
+	return flags
+}
+
+// terraformModules returns the modules jf terraform publish finds below dir,
+// the directories holding .tf files. The subdirectories of a module are part
+// of it.
+func terraformModules(dir string) ([]string, error) {
+	var modules []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			// such as the .terraform directory of terraform init
+			return filepath.SkipDir
+		}
+		tfFiles, err := filepath.Glob(filepath.Join(path, "*.tf"))
+		if err != nil {
+			return err
+		}
+		if len(tfFiles) > 0 {
+			modules = append(modules, filepath.ToSlash(path))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return modules, err
+}
+
+func GetTfPublishCommandArgs(args Args) ([]Command, error) {
+
+	var cmdList []Command
+
+	opts, err := NewTfOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.Validate()...); err != nil {
+		return cmdList, err
+	}
+
+	modules, err := terraformModules(opts.ModulesDir)
+	if err != nil {
+		return cmdList, fmt.Errorf("error reading terraform modules: %s", err)
+	}
+	if len(modules) == 0 {
+		return cmdList, fmt.Errorf("no terraform modules found in %s", opts.ModulesDir)
+	}
+	logrus.Println("Terraform modules: ", strings.Join(modules, ", "))
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	opts.ServerIdDeploy = valueOrDefault(opts.ServerIdDeploy, serverId)
+	tfConfigCommand := Command{Args: append([]string{TfConfig}, opts.ConfigFlags()...)}
+
+	tfPublishCommand := Command{Args: []string{TfCmd, Publish}}
+	tfPublishCommand.Args = append(tfPublishCommand.Args, opts.PublishFlags()...)
+	tfPublishCommand.Args = append(tfPublishCommand.Args, moduleBuildFlags(args)...)
+
+	// jf publishes the modules below its working directory, using the
+	// terraform-config of that directory
+	if dir := filepath.Clean(opts.ModulesDir); dir != "." {
+		tfConfigCommand.Dir = dir
+		tfPublishCommand.Dir = dir
+	}
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, Command{Args: jfrogConfigAddConfigCommandArgs})
+	cmdList = append(cmdList, tfConfigCommand)
+	cmdList = append(cmdList, tfPublishCommand)
+	cmdList = append(cmdList, Command{Args: rtPublishBuildInfoCommandArgs})
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, Command{Args: buildDiscardCmd})
+	}
+
+	return cmdList, nil
+}
@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTfModules creates terraform modules below dir, one per path.
+func writeTfModules(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		moduleDir := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(moduleDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte("variable \"name\" {}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTerraformModules(t *testing.T) {
+	dir := t.TempDir()
+	writeTfModules(t, dir, "modules/vpc", "modules/vpc/nested", "modules/dns", ".terraform/modules/cached")
+
+	modules, err := terraformModules(filepath.Join(dir, "modules"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{filepath.ToSlash(filepath.Join(dir, "modules", "dns")), filepath.ToSlash(filepath.Join(dir, "modules", "vpc"))}
+	if strings.Join(modules, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected modules %v, got %v", want, modules)
+	}
+}
+
+func TestGetTfPublishCommandArgs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTfModules(t, ".", "infra/vpc")
+
+	args := Args{
+		Command:     "terraform-publish",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		ModulesDir:  "infra",
+		Namespace:   "acme",
+		Provider:    "aws",
+		Exclusions:  "*test*,*.md",
+		RepoDeploy:  "terraform-local",
+		DeployerId:  RtDeployerId,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	args.Tag.Name = "v1.4.0"
+
+	cmdList, err := GetRtCommandsList(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantCmds := []string{
+		"config add " + RtDeployerId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+		"terraform-config --repo-deploy=terraform-local --server-id-deploy=" + RtDeployerId,
+		"terraform publish --namespace=acme --provider=aws --tag=v1.4.0 --exclusions=*test*;*.md " +
+			"--build-name=t2 --build-number=v1.0",
+		"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := commandLine(cmd); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+	for i, wantDir := range []string{"", "infra", "infra", ""} {
+		if cmdList[i].Dir != wantDir {
+			t.Errorf("Expected command %d to run in %q, got %q", i, wantDir, cmdList[i].Dir)
+		}
+	}
+
+	var buf bytes.Buffer
+	PrintDryRunPlan(&buf, args, cmdList[2:3])
+	if want := "+ cd infra && jf terraform publish --namespace=acme"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected plan to contain %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestGetTfPublishCommandArgsWithoutModules(t *testing.T) {
+	t.Chdir(t.TempDir())
+	args := Args{RepoDeploy: "terraform-local", Namespace: "acme", Provider: "aws", ModuleTag: "1.0.0"}
+
+	_, err := GetTfPublishCommandArgs(args)
+	want := "no terraform modules found in ."
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+
+	args.ModuleTag = ""
+	_, err = GetTfPublishCommandArgs(args)
+	want = "PLUGIN_MODULE_TAG needs to be set when the pipeline has no tag"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+}
+
+func TestHandleRtCommandsRunsTfPublishInModulesDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTfModules(t, ".", "infra/vpc")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{
+		Command:     "terraform-publish",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		ModulesDir:  "infra",
+		Namespace:   "acme",
+		Provider:    "aws",
+		ModuleTag:   "1.0.0",
+		RepoDeploy:  "terraform-local",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+	dirs := map[string]string{}
+	executor := requestFunc(func(ctx context.Context, req ExecRequest) (ExecResult, error) {
+		dirs[jfSubcommand(req.Argv)] = req.Dir
+		return ExecResult{}, nil
+	})
+	if err := HandleRtCommands(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := dirs[TfCmd]; got != "infra" {
+		t.Errorf("Expected terraform publish to run in infra, got %q", got)
+	}
+	if got := dirs["rt "+BuildPublish]; got != "" {
+		t.Errorf("Expected build-publish to run in the working directory, got %q", got)
+	}
+	if got, err := os.Getwd(); err != nil || got != wd {
+		t.Errorf("Expected the working directory to stay %s, got %s", wd, got)
+	}
+}
@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// validateCommand is the PLUGIN_COMMAND running the preflight validation.
+const validateCommand = "validate"
+
+// validateHelp describes validateCommand in RtCommandsHelp.
+const validateHelp = "check the settings of the step without running any command"
+
+// isValidateOnly reports whether the step only validates its settings.
+func isValidateOnly(args Args) bool {
+	return args.ValidateOnly || normalizeRtName(args.Command) == validateCommand
+}
+
+// ValidateArgs checks the settings of every command the step would run:
+// required and mutually exclusive fields, the Artifactory URL, the
+// credentials and the files named by the settings. It returns all the
+// problems found, joined, or nil when the step can run.
+func ValidateArgs(args Args) error {
+	var problems []error
+	for _, command := range validatedCommands(args) {
+		rtCmd, err := LookupRtCommand(args.BuildTool, command)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for _, err := range rtCmd.Check(args) {
+			problems = append(problems, fmt.Errorf("command %q: %w", rtCmd.Name, err))
+		}
+	}
+
+	if args.URL != "" {
+		if _, err := sanitizeURL(args.URL); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if _, err := NewRetryPolicy(args); err != nil {
+		problems = append(problems, err)
+	}
+	return errors.Join(problems...)
+}
+
+// validatedCommands returns the commands the step would run.
+func validatedCommands(args Args) []string {
+	var commands []string
+	for _, command := range args.Commands {
+		if strings.TrimSpace(command) != "" {
+			commands = append(commands, command)
+		}
+	}
+	switch {
+	case len(commands) > 0:
+		return commands
+	case args.Command != "" && normalizeRtName(args.Command) != validateCommand:
+		return []string{args.Command}
+	case args.BuildTool != "":
+		return []string{""}
+	}
+	return []string{"upload"}
+}
+
+// Check returns the problems of the settings of args for the command.
+func (c *RtCommand) Check(args Args) []error {
+	var problems []error
+	if missing := c.MissingFields(args); len(missing) > 0 {
+		problems = append(problems, fmt.Errorf("missing mandatory fields: %s", strings.Join(missing, ", ")))
+	}
+
+	v := reflect.ValueOf(args)
+	tagMap := getTagMapping(v.Type())
+	isSet := func(tag string) bool {
+		fieldIndex, found := tagMap[tag]
+		return found && !v.Field(fieldIndex).IsZero()
+	}
+	for _, group := range c.ExclusiveFields {
+		var set []string
+		for _, tag := range group {
+			if isSet(tag) {
+				set = append(set, tag)
+			}
+		}
+		if len(set) > 1 {
+			problems = append(problems, fmt.Errorf("only one of %s may be set", strings.Join(set, ", ")))
+		}
+	}
+	for _, tag := range c.FileFields {
+		if !isSet(tag) {
+			continue
+		}
+		path := fmt.Sprint(v.Field(tagMap[tag]).Interface())
+		if _, err := os.Stat(path); err != nil {
+			problems = append(problems, fmt.Errorf("%s: file %s not found", tag, path))
+		}
+	}
+	if !c.NoAuth {
+		problems = append(problems, checkAuth(args)...)
+	}
+	if c.Validate != nil {
+		problems = append(problems, c.Validate(args)...)
+	}
+	return problems
+}
+
+// checkAuth returns the problems of the credentials of args. Like
+// setAuthParams, it accepts any set of credentials one of which jf config add
+// can use, in the order username/password, api key, access token.
+func checkAuth(args Args) []error {
+	if _, err := setAuthParams(nil, args); err == nil {
+		return nil
+	}
+	if (args.Username == "") != (args.Password == "") {
+		return []error{errors.New("PLUGIN_USERNAME and PLUGIN_PASSWORD must be set together")}
+	}
+	return []error{errors.New("either username/password, api key or access token needs to be set")}
+}
+
+// logValidation logs the outcome of the preflight validation.
+func logValidation(err error) {
+	if err == nil {
+		logrus.Println("Validation passed, the settings are valid for every command")
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("Validation failed:\n")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(&sb, "  - %s\n", line)
+	}
+	logrus.Print(sb.String())
+}
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateArgsValid(t *testing.T) {
+	pomFile := filepath.Join(t.TempDir(), "pom.xml")
+	if err := os.WriteFile(pomFile, []byte("<project/>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []Args{
+		{AccessToken: RtAccessToken, URL: RtUrlTestStr, Source: "dist/app.jar", Target: "libs-release-local/"},
+		{BuildTool: "mvn", Command: validateCommand, Username: "ab", Password: "cd", URL: RtUrlTestStr,
+			MvnGoals: "clean install", MvnPomFile: pomFile},
+		{Commands: []string{"publish-build-info", "cleanup"}, ValidateOnly: true, APIKey: "key",
+			URL: RtUrlTestStr, BuildName: RtBuildName, BuildNumber: RtBuildNumber},
+	}
+	for i, args := range tests {
+		if err := ValidateArgs(args); err != nil {
+			t.Errorf("Case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestValidateArgsReportsAllProblems(t *testing.T) {
+	args := Args{
+		Commands:    []string{"upload", "promote", "build-discard", "download", "gradle-publish"},
+		Username:    "ab",
+		URL:         "https://artifactory.test.io/",
+		Spec:        "missing-spec.json",
+		Source:      "dist/app.jar",
+		SpecPath:    "missing-download-spec.json",
+		BuildNumber: RtBuildNumber,
+	}
+	err := ValidateArgs(args)
+	if err == nil {
+		t.Fatalf("Expected validation errors")
+	}
+
+	for _, want := range []string{
+		`command "upload": only one of PLUGIN_SPEC, PLUGIN_SOURCE may be set`,
+		`command "upload": PLUGIN_SPEC: file missing-spec.json not found`,
+		`command "upload": PLUGIN_USERNAME and PLUGIN_PASSWORD must be set together`,
+		`command "promote": missing mandatory fields: PLUGIN_BUILD_NAME`,
+		`command "promote": PLUGIN_TARGET needs to be set`,
+		`command "build-discard": missing mandatory fields: PLUGIN_BUILD_NAME`,
+		`command "download": only one of PLUGIN_SPEC, PLUGIN_SPEC_PATH may be set`,
+		`command "download": PLUGIN_SPEC_PATH: file missing-download-spec.json not found`,
+		`unknown command "gradle-publish"`,
+		`url does not contain '/artifactory'`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected problems to contain %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{Username: "ab", Password: "cd"}, ""},
+		{Args{AccessToken: RtAccessToken}, ""},
+		{Args{}, "either username/password, api key or access token needs to be set"},
+		{Args{Username: "ab", APIKey: "key"}, ""},
+		{Args{Password: "cd", APIKey: "key"}, ""},
+		{Args{APIKey: "key", AccessToken: RtAccessToken}, ""},
+		{Args{Username: "ab", Password: "cd", AccessToken: RtAccessToken}, ""},
+		{Args{Username: "ab"}, "PLUGIN_USERNAME and PLUGIN_PASSWORD must be set together"},
+		{Args{Password: "cd"}, "PLUGIN_USERNAME and PLUGIN_PASSWORD must be set together"},
+	}
+	for _, tc := range tests {
+		problems := checkAuth(tc.args)
+		if tc.want == "" {
+			if len(problems) > 0 {
+				t.Errorf("Unexpected problems: %v", problems)
+			}
+			continue
+		}
+		if len(problems) != 1 || problems[0].Error() != tc.want {
+			t.Errorf("Expected %q, got %v", tc.want, problems)
+		}
+	}
+}
+
+func TestExecValidateOnlyDoesNotExecute(t *testing.T) {
+	args := Args{
+		Command:     validateCommand,
+		Commands:    []string{"promote"},
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Target:      "libs-release",
+	}
+	executor := &recordingExecutor{}
+	if err := ExecWithExecutor(context.Background(), args, executor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(executor.commands) != 0 {
+		t.Errorf("Expected no command to run, got %v", executor.commands)
+	}
+}
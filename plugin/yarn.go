@@ -0,0 +1,236 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// nodePackagesDir is the directory of the run yarn and pnpm pack the
+// package to.
+const nodePackagesDir = "packages"
+
+// NodeOptions are the settings of the yarn and pnpm commands.
+type NodeOptions struct {
+	Args            string `split_words:"true"`
+	RepoResolve     string `split_words:"true"`
+	RepoDeploy      string `split_words:"true"`
+	ResolverId      string `split_words:"true"`
+	DeployerId      string `split_words:"true"`
+	ServerIdResolve string `split_words:"true"`
+	Global          bool   `split_words:"true"`
+	DetailedSummary bool   `split_words:"true"`
+	Threads         int    `split_words:"true"`
+}
+
+// NewYarnOptions returns the yarn options of args, overridden by the
+// PLUGIN_YARN_ environment variables.
+func NewYarnOptions(args Args) (NodeOptions, error) {
+	return newNodeOptions(YarnOptionsPrefix, args.YarnArgs, args)
+}
+
+// NewPnpmOptions returns the pnpm options of args, overridden by the
+// PLUGIN_PNPM_ environment variables.
+func NewPnpmOptions(args Args) (NodeOptions, error) {
+	return newNodeOptions(PnpmOptionsPrefix, args.PnpmArgs, args)
+}
+
+func newNodeOptions(prefix, toolArgs string, args Args) (NodeOptions, error) {
+	opts := NodeOptions{
+		Args:            toolArgs,
+		RepoResolve:     args.RepoResolve,
+		RepoDeploy:      args.RepoDeploy,
+		ResolverId:      args.ResolverId,
+		DeployerId:      args.DeployerId,
+		ServerIdResolve: args.ServerIdResolve,
+		Global:          args.Global,
+		DetailedSummary: args.DetailedSummary,
+		Threads:         args.Threads,
+	}
+	return opts, loadOptions(prefix, &opts)
+}
+
+// Validate returns the problems of the yarn and pnpm options.
+func (o NodeOptions) Validate() []error {
+	return notNegative("PLUGIN_THREADS", o.Threads)
+}
+
+// ValidateBuild returns the problems of the options for installing the
+// dependencies.
+func (o NodeOptions) ValidateBuild() []error {
+	problems := o.Validate()
+	if o.RepoResolve == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_RESOLVE needs to be set"))
+	}
+	return problems
+}
+
+// ValidatePublish returns the problems of the options for publishing the
+// package.
+func (o NodeOptions) ValidatePublish() []error {
+	problems := o.Validate()
+	if o.RepoDeploy == "" {
+		problems = append(problems, errors.New("PLUGIN_REPO_DEPLOY needs to be set"))
+	}
+	return problems
+}
+
+// ConfigFlags returns the flags of jf yarn-config.
+func (o NodeOptions) ConfigFlags() []string {
+	var flags cmdFlags
+	flags.addBool("--global", o.Global)
+	flags.addString("--repo-resolve", o.RepoResolve)
+	flags.addString("--server-id-resolve", o.ServerIdResolve)
+	return flags
+}
+
+func GetYarnBuildCommandArgs(args Args) ([][]string, error) {
+
+	var cmdList [][]string
+
+	opts, err := NewYarnOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidateBuild()...); err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.ResolverId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
+	opts.ServerIdResolve = valueOrDefault(opts.ServerIdResolve, serverId)
+	yarnConfigCommandArgs := append([]string{YarnConfig}, opts.ConfigFlags()...)
+
+	yarnArgs, err := splitArgs(opts.Args)
+	if err != nil {
+		return cmdList, fmt.Errorf("invalid PLUGIN_YARN_ARGS: %w", err)
+	}
+	yarnInstallCommandArgs := append([]string{YarnCmd, "install"}, yarnArgs...)
+	yarnInstallCommandArgs = append(yarnInstallCommandArgs, moduleBuildFlags(args)...)
+	var runFlags cmdFlags
+	runFlags.addInt("--threads", opts.Threads)
+	yarnInstallCommandArgs = append(yarnInstallCommandArgs, runFlags...)
+
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
+	cmdList = append(cmdList, yarnConfigCommandArgs)
+	cmdList = append(cmdList, yarnInstallCommandArgs)
+
+	return cmdList, nil
+}
+
+// nodePublishCommand returns the builder packing the package with the
+// external pack command of tool and uploading it with build info.
+func nodePublishCommand(tool string) RtCommandBuilder {
+	return func(args Args) ([]Command, error) {
+		return GetNodePublishCommandArgs(tool, args)
+	}
+}
+
+// GetNodePublishCommandArgs returns the commands publishing a yarn or pnpm
+// package. jf yarn records no build info when publishing, so the package is
+// packed and uploaded to the npm repository, which indexes it.
+func GetNodePublishCommandArgs(tool string, args Args) ([]Command, error) {
+
+	var cmdList []Command
+
+	newOptions := NewYarnOptions
+	if tool == PnpmCmd {
+		newOptions = NewPnpmOptions
+	}
+	opts, err := newOptions(args)
+	if err != nil {
+		return cmdList, err
+	}
+	if err := errors.Join(opts.ValidatePublish()...); err != nil {
+		return cmdList, err
+	}
+
+	name, err := nodePackageName()
+	if err != nil {
+		return cmdList, err
+	}
+
+	serverId := serverIDOrDefault(args, opts.DeployerId)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
+		return cmdList, err
+	}
+
+	destination := tempFilePath(args, nodePackagesDir)
+	if args.workDir != "" {
+		if err := os.MkdirAll(destination, 0700); err != nil {
+			return cmdList, fmt.Errorf("error creating package directory: %s", err)
+		}
+	}
+	destination = filepath.ToSlash(destination)
+
+	packCommand := Command{Program: tool, Args: []string{"pack"}}
+	if tool == PnpmCmd {
+		packCommand.Args = append(packCommand.Args, "--pack-destination", destination)
+	} else {
+		packCommand.Args = append(packCommand.Args, "--out", destination+"/%s-%v.tgz")
+	}
+
+	// deploy to the npm layout of the package, e.g. @acme/ui/-/
+	rtUploadCommandArgs := []string{"rt", "u", destination + "/*.tgz",
+		strings.Trim(opts.RepoDeploy, "/") + "/" + name + "/-/", "--server-id=" + serverId, "--flat=true"}
+	rtUploadCommandArgs = append(rtUploadCommandArgs, moduleBuildFlags(args)...)
+	var uploadFlags cmdFlags
+	uploadFlags.addInt("--threads", opts.Threads)
+	uploadFlags.addBool("--detailed-summary", opts.DetailedSummary)
+	rtUploadCommandArgs = append(rtUploadCommandArgs, uploadFlags...)
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
+	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+
+	cmdList = append(cmdList, Command{Args: jfrogConfigAddConfigCommandArgs})
+	cmdList = append(cmdList, packCommand)
+	cmdList = append(cmdList, Command{Args: rtUploadCommandArgs})
+	cmdList = append(cmdList, Command{Args: rtPublishBuildInfoCommandArgs})
+
+	if IsBuildDiscardArgs(args) {
+		buildDiscardCmd, err := buildDiscardCommand(args, serverId)
+		if err != nil {
+			return cmdList, err
+		}
+		cmdList = append(cmdList, Command{Args: buildDiscardCmd})
+	}
+
+	return cmdList, nil
+}
+
+// nodePackageName returns the name of the package.json of the working
+// directory.
+func nodePackageName() (string, error) {
+	content, err := os.ReadFile("package.json")
+	if err != nil {
+		return "", fmt.Errorf("error reading package.json: %s", err)
+	}
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return "", fmt.Errorf("error reading package.json: %s", err)
+	}
+	if pkg.Name == "" {
+		return "", errors.New("package.json has no name")
+	}
+	return pkg.Name, nil
+}
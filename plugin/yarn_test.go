@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetYarnBuildCommandArgs(t *testing.T) {
+	args := Args{
+		BuildTool:   "yarn",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		RepoResolve: "npm-virtual",
+		YarnArgs:    "--immutable",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		Threads:     4,
+	}
+	cmdList, err := GetRtCommandsList(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add tmpServerId --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+		"yarn-config --repo-resolve=npm-virtual --server-id-resolve=tmpServerId",
+		"yarn install --immutable --build-name=t2 --build-number=v1.0 --threads=4",
+	}
+	if len(cmdList) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+	}
+	for i, cmd := range cmdList {
+		if got := commandLine(cmd); got != wantCmds[i] {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+		}
+	}
+}
+
+func TestGetNodePublishCommandArgs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("package.json", []byte(`{"name": "@acme/ui", "version": "1.2.0"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		buildTool string
+		wantPack  string
+	}{
+		{buildTool: "yarn", wantPack: "yarn pack --out packages/%s-%v.tgz"},
+		{buildTool: "pnpm", wantPack: "pnpm pack --pack-destination packages"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.buildTool, func(t *testing.T) {
+			args := Args{
+				BuildTool:   tc.buildTool,
+				Command:     "publish",
+				AccessToken: RtAccessToken,
+				URL:         RtUrlTestStr,
+				RepoDeploy:  "npm-local",
+				DeployerId:  RtDeployerId,
+				BuildName:   RtBuildName,
+				BuildNumber: RtBuildNumber,
+			}
+			cmdList, err := GetRtCommandsList(args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			wantCmds := []string{
+				"config add " + RtDeployerId + " --url=" + RtUrlTestStr + " --access-token-stdin --interactive=false",
+				tc.wantPack,
+				"rt u packages/*.tgz npm-local/@acme/ui/-/ --server-id=" + RtDeployerId +
+					" --flat=true --build-name=t2 --build-number=v1.0",
+				"rt build-publish t2 v1.0 --server-id=" + RtDeployerId,
+			}
+			if len(cmdList) != len(wantCmds) {
+				t.Fatalf("Expected %d commands, got %d: %v", len(wantCmds), len(cmdList), cmdList)
+			}
+			for i, cmd := range cmdList {
+				if got := commandLine(cmd); got != wantCmds[i] {
+					t.Errorf("Expected: %s, Got: %s", wantCmds[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNodePublishCommandArgsWithoutPackageName(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("package.json", []byte(`{"private": true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := GetNodePublishCommandArgs(YarnCmd, Args{RepoDeploy: "npm-local"})
+	want := "package.json has no name"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected error: %s, Got: %v", want, err)
+	}
+}